@@ -1,8 +1,14 @@
 package rdf
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 const testFile = "testfile.rdf"
@@ -101,3 +107,218 @@ func _TestWriteFormat(t *testing.T) {
 	documentReader.Close()
 	r.Close()
 }
+
+// Valid RDF followed by junk must be reported, not passed to goraptor.
+func TestParseTrailingContent(t *testing.T) {
+	content, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Logf("The RDF package should contain a test file called %s.", testFile)
+		t.FailNow()
+	}
+	content = append(content, []byte("\n\x00\x01 junk bytes")...)
+
+	if _, err := Parse(bytes.NewReader(content), "rdf"); err == nil {
+		t.Error("No error for trailing content in strict mode.")
+	}
+
+	parser := MustNewParser(bytes.NewReader(content), "rdf")
+	defer parser.Free()
+	parser.Strict = false
+	doc, err := parser.Parse()
+	if err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+	if doc == nil {
+		t.Error("No document parsed.")
+	}
+	if len(parser.Warnings()) != 1 {
+		t.Errorf("Expected one warning but found %+v", parser.Warnings())
+	}
+}
+
+// Parse the test file twice with the same Parser.
+func TestParserReset(t *testing.T) {
+	data, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("The RDF package should contain a test file called %s.", testFile)
+	}
+
+	parser := MustNewParser(bytes.NewReader(data), "rdf")
+	defer parser.Free()
+	parsed1, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	parser.Reset(bytes.NewReader(data), "rdf")
+	parsed2, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Unexpected error after Reset: %s", err)
+	}
+	if parsed1 == parsed2 || !parsed1.Equal(parsed2) {
+		t.Error("Documents are not the same.")
+	}
+}
+
+func TestParseWithScore(t *testing.T) {
+	documentReader, err := os.Open(testFile)
+	if err != nil {
+		t.Fatalf("The RDF package should contain a test file called %s.", testFile)
+	}
+	defer documentReader.Close()
+
+	doc, score, err := ParseWithScore(documentReader, "rdf")
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if doc == nil || score < 50 {
+		t.Errorf("Test file scored %d.", score)
+	}
+}
+
+func TestParseConcurrent(t *testing.T) {
+	data, err := ioutil.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("The RDF package should contain a test file called %s.", testFile)
+	}
+
+	inputs := make([]io.Reader, 8)
+	for i := range inputs {
+		inputs[i] = bytes.NewReader(data)
+	}
+	inputs[3] = bytes.NewReader([]byte("not rdf"))
+
+	docs, errs := ParseConcurrent(inputs, "rdf")
+	if len(docs) != len(inputs) || len(errs) != len(inputs) {
+		t.Fatalf("Found %d documents and %d errors for %d inputs.", len(docs), len(errs), len(inputs))
+	}
+	for i := range inputs {
+		if i == 3 {
+			if errs[i] == nil {
+				t.Error("No error for invalid input.")
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("Unexpected error for input %d: %s", i, errs[i])
+		} else if !docs[i].Equal(docs[0]) {
+			t.Errorf("Document %d is not the same as the first one.", i)
+		}
+	}
+}
+
+// N-Triples input whose last statement gives the package an incompatible type.
+const partialInput = `_:doc <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://spdx.org/rdf/terms#SpdxDocument> .
+_:doc <http://spdx.org/rdf/terms#specVersion> "SPDX-1.2" .
+_:pkg <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://spdx.org/rdf/terms#Package> .
+_:pkg <http://spdx.org/rdf/terms#name> "pkg" .
+_:doc <http://spdx.org/rdf/terms#describesPackage> _:pkg .
+_:pkg <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://spdx.org/rdf/terms#File> .
+`
+
+// Parses input from an io.Pipe whose writer ends with writeEnd and checks that
+// Parse returns an error and the writer is not left blocked.
+func parsePipe(t *testing.T, input string, writeEnd func(*io.PipeWriter)) {
+	pr, pw := io.Pipe()
+	done := make(chan bool)
+	go func() {
+		pw.Write([]byte(input))
+		writeEnd(pw)
+		close(done)
+	}()
+	parsed := make(chan error)
+	go func() {
+		parser := MustNewParser(pr, FormatNTriples)
+		defer parser.Free()
+		_, err := parser.Parse()
+		parsed <- err
+	}()
+	select {
+	case err := <-parsed:
+		if err == nil {
+			t.Error("No error.")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Parse is blocked.")
+	}
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("The writer is blocked.")
+	}
+}
+
+func TestParsePipe(t *testing.T) {
+	// error in the middle of the input: the rest is not read
+	more := strings.Repeat("_:x <http://spdx.org/rdf/terms#name> \"x\" .\n", 10000)
+	parsePipe(t, partialInput, func(pw *io.PipeWriter) {
+		pw.Write([]byte(more))
+		pw.Close()
+	})
+	// the reader side fails
+	parsePipe(t, partialInput[:strings.Index(partialInput, "_:pkg")], func(pw *io.PipeWriter) {
+		pw.CloseWithError(errors.New("connection reset"))
+	})
+}
+
+func TestParseReturnPartial(t *testing.T) {
+	parser := MustNewParser(bytes.NewReader([]byte(partialInput)), FormatNTriples)
+	defer parser.Free()
+	doc, err := parser.Parse()
+	if err == nil {
+		t.Fatal("No error for incompatible types.")
+	}
+	if doc == nil {
+		t.Fatal("No partial document returned.")
+	}
+	if doc.SpecVersion.Val != "SPDX-1.2" {
+		t.Errorf("Wrong spec version: %#v", doc.SpecVersion)
+	}
+	if len(doc.Packages) != 1 || doc.Packages[0].Name.Val != "pkg" {
+		t.Errorf("Wrong packages: %#v", doc.Packages)
+	}
+
+	parser.Reset(bytes.NewReader([]byte(partialInput)), FormatNTriples)
+	parser.ReturnPartial = false
+	doc, err = parser.Parse()
+	if err == nil {
+		t.Error("No error for incompatible types.")
+	}
+	if doc != nil {
+		t.Errorf("Document returned without ReturnPartial: %#v", doc)
+	}
+
+	parser.Reset(bytes.NewReader([]byte(partialInput)), FormatNTriples)
+	docs, err := parser.ParseMany()
+	if err == nil {
+		t.Error("No error for incompatible types.")
+	}
+	if docs != nil {
+		t.Errorf("Documents returned without ReturnPartial: %#v", docs)
+	}
+}
+
+const manyInput = `<http://a.example/doc#SPDXRef-DOCUMENT> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://spdx.org/rdf/terms#SpdxDocument> .
+<http://a.example/doc#SPDXRef-DOCUMENT> <http://spdx.org/rdf/terms#describesPackage> <http://a.example/doc#SPDXRef-1> .
+<http://a.example/doc#SPDXRef-1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://spdx.org/rdf/terms#Package> .
+<http://b.example/doc#SPDXRef-DOCUMENT> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://spdx.org/rdf/terms#SpdxDocument> .
+<http://b.example/doc#SPDXRef-DOCUMENT> <http://spdx.org/rdf/terms#describesPackage> <http://b.example/doc#SPDXRef-1> .
+<http://b.example/doc#SPDXRef-1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://spdx.org/rdf/terms#Package> .
+`
+
+func TestParseManyUriDocuments(t *testing.T) {
+	parser := MustNewParser(bytes.NewReader([]byte(manyInput)), FormatNTriples)
+	defer parser.Free()
+	docs, err := parser.ParseMany()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("Found %d documents (expected 2).", len(docs))
+	}
+	for _, doc := range docs {
+		if doc.Id.Val != "SPDXRef-DOCUMENT" || len(doc.Packages) != 1 {
+			t.Errorf("Wrong document: %#v", doc)
+		}
+	}
+}
@@ -25,10 +25,32 @@ const (
 	Fmt_nquads       = "nquads"        // for N-Quads
 )
 
+// Formats supported for parsing SPDX documents. See SupportedFormats().
+const (
+	FormatRDFXML   = Fmt_rdfxml   // RDF/XML
+	FormatNTriples = Fmt_ntriples // N-Triples
+	FormatTurtle   = Fmt_turtle   // Turtle Terse RDF Triple Language
+	FormatGuess    = "guess"      // let raptor guess the syntax from the input
+)
+
+// Returns the formats that NewParser() accepts. The value "rdf" and the RDF/XML
+// writer formats Fmt_rdfxmlAbbrev and Fmt_rdfxmlXmp are also accepted: the
+// first means FormatGuess and the others are read as FormatRDFXML.
+func SupportedFormats() []string {
+	return []string{FormatRDFXML, FormatNTriples, FormatTurtle, FormatGuess}
+}
+
 // Useful RDF URIs
 const (
 	baseUri    = "http://spdx.org/rdf/terms#"
 	licenceUri = "http://spdx.org/licenses/"
+
+	// Namespace of the external reference types listed by SPDX.
+	referenceTypeUri = "http://spdx.org/rdf/references/"
+
+	// Terms namespace used by older SPDX RDF documents. It is read as an
+	// alias of baseUri.
+	obsoleteBaseUri = "http://spdx.org/rdf/terms2#"
 )
 
 // Common RDF prefixes used in SPDX RDF Representations.
@@ -36,9 +58,29 @@ var rdfPrefixes = map[string]string{
 	"ns:":   "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
 	"doap:": "http://usefulinc.com/ns/doap#",
 	"rdfs:": "http://www.w3.org/2000/01/rdf-schema#",
+	"ptr:":  "http://www.w3.org/2009/pointers#",
 	"":      baseUri,
 }
 
+// Prefixes of the namespaces used by SPDX documents. A URI that starts with
+// one of them comes from a prefixed name whose prefix isn't bound.
+var requiredPrefixes = []string{"spdx:", "doap:", "rdfs:", "rdf:"}
+
+// Returns the unbound prefix t starts with, if t is a URI starting with one of
+// requiredPrefixes. Returns the empty string otherwise.
+func unboundPrefix(t goraptor.Term) string {
+	u, ok := t.(*goraptor.Uri)
+	if !ok {
+		return ""
+	}
+	for _, pfx := range requiredPrefixes {
+		if strings.HasPrefix(string(*u), pfx) {
+			return pfx
+		}
+	}
+	return ""
+}
+
 // Useful helper pair struct.
 type pair struct {
 	key, val string
@@ -70,7 +112,7 @@ func FormatOk(fmt string) bool {
 	return false
 }
 
-// Expands the prefixes "ns:", "doap:" and "rdfs:" to their full URIs.
+// Expands the prefixes "ns:", "doap:", "rdfs:" and "ptr:" to their full URIs.
 // If there is no ":" or there is another prefix, it expands to baseUri.
 func prefix(k string) *goraptor.Uri {
 	var pref string
@@ -86,9 +128,13 @@ func prefix(k string) *goraptor.Uri {
 	return &uri
 }
 
-// Change the RDF prefixes to their short forms.
+// Change the RDF prefixes to their short forms. Terms in the obsolete terms
+// namespace are shortened as if they were in baseUri.
 func shortPrefix(t goraptor.Term) string {
 	str := termStr(t)
+	if strings.HasPrefix(str, obsoleteBaseUri) {
+		return str[len(obsoleteBaseUri):]
+	}
 	for short, long := range rdfPrefixes {
 		if strings.HasPrefix(str, long) {
 			return strings.Replace(str, long, short, 1)
@@ -97,6 +143,17 @@ func shortPrefix(t goraptor.Term) string {
 	return str
 }
 
+// Returns t with the obsolete terms namespace replaced by baseUri. The second
+// value is true if t was in the obsolete namespace. Terms other than URIs are
+// returned unchanged.
+func normalizeTerm(t goraptor.Term) (goraptor.Term, bool) {
+	u, ok := t.(*goraptor.Uri)
+	if !ok || !strings.HasPrefix(string(*u), obsoleteBaseUri) {
+		return t, false
+	}
+	return uri(baseUri + string(*u)[len(obsoleteBaseUri):]), true
+}
+
 // goraptor.Term to string. Returns empty string if the term given is not one of
 // the following types: *goraptor.Uri, *goraptor.Blank or *goraptor.Literal.
 func termStr(term goraptor.Term) string {
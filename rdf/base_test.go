@@ -33,6 +33,7 @@ func TestShortPrefix(t *testing.T) {
 		"ns:something": "http://www.w3.org/1999/02/22-rdf-syntax-ns#something",
 		"ns:":          "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
 		"":             baseUri,
+		"fileName":     obsoleteBaseUri + "fileName",
 	}
 	for short, long := range tests {
 		res := shortPrefix(uri(long))
@@ -0,0 +1,218 @@
+package rdf
+
+import (
+	"fmt"
+	"github.com/vladvelici/spdx-go/spdx"
+	"strings"
+)
+
+// Licence expression error messages.
+const (
+	msgInvalidExpression = "Invalid licence expression %q: %s"
+	msgUnexpectedToken   = "unexpected %q"
+	msgUnexpectedEnd     = "unexpected end of expression"
+)
+
+// Parses a licence expression such as "MIT OR (Apache-2.0 AND GPL-2.0 WITH
+// Classpath-exception-2.0)" into the corresponding licence tree. The operators
+// AND, OR and WITH are case-insensitive. WITH binds tighter than AND, which
+// binds tighter than OR. Sequences of the same operator are grouped in a single
+// licence set.
+//
+// The leaves are created using spdx.NewLicence. All the created licences and
+// sets have `meta` as metadata. A *spdx.ParseError is returned if the
+// expression is not valid.
+func ParseLicenceExpression(expr string, meta *spdx.Meta) (spdx.AnyLicence, error) {
+	ep := &exprParser{tokens: tokenizeExpression(expr), meta: meta}
+	lic, err := ep.or()
+	if err == nil && ep.pos < len(ep.tokens) {
+		err = fmt.Errorf(msgUnexpectedToken, ep.tokens[ep.pos])
+	}
+	if err != nil {
+		return nil, spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgInvalidExpression, expr, err), meta)
+	}
+	return lic, nil
+}
+
+// Splits a licence expression into parentheses and words.
+func tokenizeExpression(expr string) []string {
+	expr = strings.Replace(expr, "(", " ( ", -1)
+	expr = strings.Replace(expr, ")", " ) ", -1)
+	return strings.Fields(expr)
+}
+
+// Recursive descent parser for licence expressions.
+type exprParser struct {
+	tokens []string
+	pos    int
+	meta   *spdx.Meta
+}
+
+// Returns the next token, or an empty string at the end of the expression.
+func (ep *exprParser) peek() string {
+	if ep.pos < len(ep.tokens) {
+		return ep.tokens[ep.pos]
+	}
+	return ""
+}
+
+// Returns whether the next token is the operator op and consumes it if so.
+func (ep *exprParser) operator(op string) bool {
+	if strings.EqualFold(ep.peek(), op) {
+		ep.pos++
+		return true
+	}
+	return false
+}
+
+// or := and ("OR" and)*
+func (ep *exprParser) or() (spdx.AnyLicence, error) {
+	lic, err := ep.and()
+	if err != nil || !strings.EqualFold(ep.peek(), "or") {
+		return lic, err
+	}
+	set := spdx.NewDisjunctiveSet(ep.meta, lic)
+	for ep.operator("or") {
+		if lic, err = ep.and(); err != nil {
+			return nil, err
+		}
+		set.Add(lic)
+	}
+	return set, nil
+}
+
+// and := with ("AND" with)*
+func (ep *exprParser) and() (spdx.AnyLicence, error) {
+	lic, err := ep.with()
+	if err != nil || !strings.EqualFold(ep.peek(), "and") {
+		return lic, err
+	}
+	set := spdx.NewConjunctiveSet(ep.meta, lic)
+	for ep.operator("and") {
+		if lic, err = ep.with(); err != nil {
+			return nil, err
+		}
+		set.Add(lic)
+	}
+	return set, nil
+}
+
+// with := primary ("WITH" exception-id)?
+func (ep *exprParser) with() (spdx.AnyLicence, error) {
+	lic, err := ep.primary()
+	if err != nil || !ep.operator("with") {
+		return lic, err
+	}
+	id := ep.peek()
+	if id == "" {
+		return nil, fmt.Errorf(msgUnexpectedEnd)
+	}
+	if isExpressionKeyword(id) {
+		return nil, fmt.Errorf(msgUnexpectedToken, id)
+	}
+	ep.pos++
+	exception := &spdx.LicenceException{Id: spdx.Str(id, ep.meta), Meta: ep.meta}
+	return spdx.NewWithException(ep.meta, lic, exception), nil
+}
+
+// primary := licence-id | "(" or ")"
+func (ep *exprParser) primary() (spdx.AnyLicence, error) {
+	tok := ep.peek()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf(msgUnexpectedEnd)
+	case tok == "(":
+		ep.pos++
+		lic, err := ep.or()
+		if err != nil {
+			return nil, err
+		}
+		if ep.peek() != ")" {
+			if ep.peek() == "" {
+				return nil, fmt.Errorf(msgUnexpectedEnd)
+			}
+			return nil, fmt.Errorf(msgUnexpectedToken, ep.peek())
+		}
+		ep.pos++
+		return lic, nil
+	case isExpressionKeyword(tok):
+		return nil, fmt.Errorf(msgUnexpectedToken, tok)
+	}
+	ep.pos++
+	return spdx.NewLicence(tok, ep.meta), nil
+}
+
+// Returns whether tok is an operator or a parenthesis.
+func isExpressionKeyword(tok string) bool {
+	switch strings.ToLower(tok) {
+	case "and", "or", "with", "(", ")":
+		return true
+	}
+	return false
+}
+
+// Formats lic as a licence expression such as "MIT OR (Apache-2.0 AND GPL-2.0
+// WITH Classpath-exception-2.0)", the reverse of ParseLicenceExpression().
+// Parentheses are only added where the precedence of the operators requires
+// them. Returns an empty string if lic is nil.
+func FormatLicenceExpression(lic spdx.AnyLicence) string {
+	switch l := lic.(type) {
+	case nil:
+		return ""
+	case *spdx.Licence:
+		return l.V()
+	case *spdx.ExtractedLicence:
+		return l.Id.V()
+	case spdx.ConjunctiveLicenceSet:
+		return formatMembers(l.Members, " AND ", func(m spdx.AnyLicence) bool {
+			_, or := derefSet(m).(spdx.DisjunctiveLicenceSet)
+			return or
+		})
+	case *spdx.ConjunctiveLicenceSet:
+		return FormatLicenceExpression(*l)
+	case spdx.DisjunctiveLicenceSet:
+		return formatMembers(l.Members, " OR ", func(spdx.AnyLicence) bool { return false })
+	case *spdx.DisjunctiveLicenceSet:
+		return FormatLicenceExpression(*l)
+	case spdx.WithException:
+		expr := FormatLicenceExpression(l.Licence)
+		switch derefSet(l.Licence).(type) {
+		case spdx.ConjunctiveLicenceSet, spdx.DisjunctiveLicenceSet, spdx.WithException:
+			expr = "(" + expr + ")"
+		}
+		if l.Exception != nil {
+			expr += " WITH " + l.Exception.Id.V()
+		}
+		return expr
+	case *spdx.WithException:
+		return FormatLicenceExpression(*l)
+	}
+	return lic.LicenceId()
+}
+
+// Formats the members of a set joined by op. Members for which paren returns
+// true are put in parentheses.
+func formatMembers(members []spdx.AnyLicence, op string, paren func(spdx.AnyLicence) bool) string {
+	exprs := make([]string, len(members))
+	for i, m := range members {
+		exprs[i] = FormatLicenceExpression(m)
+		if paren(m) {
+			exprs[i] = "(" + exprs[i] + ")"
+		}
+	}
+	return strings.Join(exprs, op)
+}
+
+// Returns the value pointed to by lic if it is a pointer to a set or to a
+// licence with an exception, lic otherwise.
+func derefSet(lic spdx.AnyLicence) spdx.AnyLicence {
+	switch l := lic.(type) {
+	case *spdx.ConjunctiveLicenceSet:
+		return *l
+	case *spdx.DisjunctiveLicenceSet:
+		return *l
+	case *spdx.WithException:
+		return *l
+	}
+	return lic
+}
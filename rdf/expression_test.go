@@ -0,0 +1,97 @@
+package rdf
+
+import (
+	"github.com/vladvelici/spdx-go/spdx"
+	"testing"
+)
+
+func TestParseLicenceExpression(t *testing.T) {
+	meta := spdx.NewMetaL(3)
+	lic := func(id string) spdx.Licence { return spdx.NewLicence(id, meta) }
+	classpath := &spdx.LicenceException{Id: spdx.Str("Classpath-exception-2.0", nil)}
+
+	tests := map[string]spdx.AnyLicence{
+		"MIT":                 lic("MIT"),
+		"(MIT)":               lic("MIT"),
+		"MIT OR Apache-2.0":   spdx.NewDisjunctiveSet(meta, lic("MIT"), lic("Apache-2.0")),
+		"MIT or GPL-2.0 OR X": spdx.NewDisjunctiveSet(meta, lic("MIT"), lic("GPL-2.0"), lic("X")),
+		"MIT OR Apache-2.0 AND GPL-2.0": spdx.NewDisjunctiveSet(meta,
+			lic("MIT"),
+			spdx.NewConjunctiveSet(meta, lic("Apache-2.0"), lic("GPL-2.0"))),
+		"((MIT OR X) AND (Y))": spdx.NewConjunctiveSet(meta,
+			spdx.NewDisjunctiveSet(meta, lic("MIT"), lic("X")),
+			lic("Y")),
+		"GPL-2.0 WITH Classpath-exception-2.0 OR MIT": spdx.NewDisjunctiveSet(meta,
+			spdx.NewWithException(meta, lic("GPL-2.0"), classpath),
+			lic("MIT")),
+	}
+	for expr, expected := range tests {
+		found, err := ParseLicenceExpression(expr, meta)
+		if err != nil {
+			t.Errorf("Unexpected error for %#v: %s", expr, err)
+			continue
+		}
+		if !spdx.SameLicence(found, expected) {
+			t.Errorf("Wrong licence for %#v. Found %s (expected %s)", expr, found.LicenceId(), expected.LicenceId())
+		}
+	}
+
+	invalid := []string{"", "MIT OR", "(MIT", "MIT)", "MIT AND OR X", "GPL-2.0 WITH", "MIT X", "WITH MIT"}
+	for _, expr := range invalid {
+		if _, err := ParseLicenceExpression(expr, meta); err == nil {
+			t.Errorf("No error for invalid expression %#v", expr)
+		} else if perr, ok := err.(*spdx.ParseError); !ok || perr.Meta != meta {
+			t.Errorf("Error is not a ParseError with the given meta: %#v", err)
+		}
+	}
+}
+
+func TestCanonicalLicenceExpression(t *testing.T) {
+	a, err := ParseLicenceExpression("(MIT AND GPL-2.0+) AND (Zlib OR ISC)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseLicenceExpression("(ISC OR Zlib) AND GPL-2.0-or-later AND MIT", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := &spdx.Document{Packages: []*spdx.Package{{LicenceDeclared: a}, {LicenceDeclared: b}}}
+	doc.CanonicalizeLicences()
+	first, second := doc.Packages[0].LicenceDeclared, doc.Packages[1].LicenceDeclared
+	if !spdx.SameLicence(first, second) || first.LicenceId() != second.LicenceId() {
+		t.Errorf("Different canonical licences: %s and %s", first.LicenceId(), second.LicenceId())
+	}
+	if expected := "((ISC or Zlib) and GPL-2.0-or-later and MIT)"; first.LicenceId() != expected {
+		t.Errorf("Wrong canonical licence: %s (expected %s)", first.LicenceId(), expected)
+	}
+}
+
+func TestFormatLicenceExpression(t *testing.T) {
+	exprs := []string{
+		"MIT",
+		"MIT OR Apache-2.0",
+		"MIT OR Apache-2.0 AND GPL-2.0",
+		"(MIT OR X) AND Y",
+		"GPL-2.0 WITH Classpath-exception-2.0 OR MIT",
+		"(GPL-2.0 OR MIT) WITH Classpath-exception-2.0",
+		"LicenseRef-1 AND (ISC OR Zlib AND MIT)",
+	}
+	for _, expr := range exprs {
+		lic, err := ParseLicenceExpression(expr, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %s", expr, err)
+		}
+		if found := FormatLicenceExpression(lic); found != expr {
+			t.Errorf("Wrong expression for %q: %q", expr, found)
+		}
+	}
+	if FormatLicenceExpression(nil) != "" {
+		t.Error("Non-empty expression for nil.")
+	}
+	extracted := &spdx.ExtractedLicence{Id: spdx.Str("LicenseRef-2", nil)}
+	conj := spdx.NewConjunctiveSet(nil, extracted, spdx.NewLicence("MIT", nil))
+	if found := FormatLicenceExpression(&conj); found != "LicenseRef-2 AND MIT" {
+		t.Errorf("Wrong expression for a set pointer: %q", found)
+	}
+}
@@ -0,0 +1,344 @@
+package rdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/vladvelici/spdx-go/spdx"
+	"io"
+)
+
+// Documents in the SPDX JSON format. Only the fields that can be filled from a
+// *spdx.Document are defined; empty fields are left out.
+type jsonDocument struct {
+	Id                   string                  `json:"SPDXID"`
+	SpecVersion          string                  `json:"spdxVersion,omitempty"`
+	DataLicence          string                  `json:"dataLicense,omitempty"`
+	Namespace            string                  `json:"documentNamespace,omitempty"`
+	Comment              string                  `json:"comment,omitempty"`
+	CreationInfo         *jsonCreationInfo       `json:"creationInfo,omitempty"`
+	ExternalDocumentRefs []*jsonExternalDocRef   `json:"externalDocumentRefs,omitempty"`
+	ExtractedLicences    []*jsonExtractedLicence `json:"hasExtractedLicensingInfos,omitempty"`
+	Packages             []*jsonPackage          `json:"packages,omitempty"`
+	Files                []*jsonFile             `json:"files,omitempty"`
+	Reviews              []*jsonReview           `json:"reviewers,omitempty"`
+	Relationships        []*jsonRelationship     `json:"relationships,omitempty"`
+}
+
+type jsonCreationInfo struct {
+	Created            string   `json:"created,omitempty"`
+	Creators           []string `json:"creators,omitempty"`
+	LicenceListVersion string   `json:"licenseListVersion,omitempty"`
+	Comment            string   `json:"comment,omitempty"`
+}
+
+type jsonExternalDocRef struct {
+	Id       string        `json:"externalDocumentId"`
+	Document string        `json:"spdxDocument,omitempty"`
+	Checksum *jsonChecksum `json:"checksum,omitempty"`
+}
+
+type jsonExtractedLicence struct {
+	Id      string   `json:"licenseId"`
+	Text    string   `json:"extractedText,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	SeeAlso []string `json:"seeAlsos,omitempty"`
+	Comment string   `json:"comment,omitempty"`
+}
+
+type jsonPackage struct {
+	Id                   string                `json:"SPDXID"`
+	Name                 string                `json:"name,omitempty"`
+	Version              string                `json:"versionInfo,omitempty"`
+	FileName             string                `json:"packageFileName,omitempty"`
+	Supplier             string                `json:"supplier,omitempty"`
+	Originator           string                `json:"originator,omitempty"`
+	DownloadLocation     string                `json:"downloadLocation,omitempty"`
+	HomePage             string                `json:"homepage,omitempty"`
+	SourceInfo           string                `json:"sourceInfo,omitempty"`
+	LicenceConcluded     string                `json:"licenseConcluded,omitempty"`
+	LicenceDeclared      string                `json:"licenseDeclared,omitempty"`
+	LicenceInfoFromFiles []string              `json:"licenseInfoFromFiles,omitempty"`
+	LicenceComments      string                `json:"licenseComments,omitempty"`
+	CopyrightText        string                `json:"copyrightText,omitempty"`
+	Summary              string                `json:"summary,omitempty"`
+	Description          string                `json:"description,omitempty"`
+	Checksums            []*jsonChecksum       `json:"checksums,omitempty"`
+	VerificationCode     *jsonVerificationCode `json:"packageVerificationCode,omitempty"`
+	ExternalRefs         []*jsonExternalRef    `json:"externalRefs,omitempty"`
+	HasFiles             []string              `json:"hasFiles,omitempty"`
+}
+
+type jsonVerificationCode struct {
+	Value         string   `json:"packageVerificationCodeValue,omitempty"`
+	ExcludedFiles []string `json:"packageVerificationCodeExcludedFiles,omitempty"`
+}
+
+type jsonExternalRef struct {
+	Category string `json:"referenceCategory,omitempty"`
+	Type     string `json:"referenceType,omitempty"`
+	Locator  string `json:"referenceLocator,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type jsonFile struct {
+	Id                string            `json:"SPDXID"`
+	Name              string            `json:"fileName,omitempty"`
+	Types             []string          `json:"fileTypes,omitempty"`
+	Checksums         []*jsonChecksum   `json:"checksums,omitempty"`
+	LicenceConcluded  string            `json:"licenseConcluded,omitempty"`
+	LicenceInfoInFile []string          `json:"licenseInfoInFiles,omitempty"`
+	LicenceComments   string            `json:"licenseComments,omitempty"`
+	CopyrightText     string            `json:"copyrightText,omitempty"`
+	Notice            string            `json:"noticeText,omitempty"`
+	Contributors      []string          `json:"fileContributors,omitempty"`
+	Comment           string            `json:"comment,omitempty"`
+	ArtifactOf        []*jsonArtifactOf `json:"artifactOf,omitempty"`
+}
+
+type jsonArtifactOf struct {
+	Name       string `json:"name,omitempty"`
+	HomePage   string `json:"homePage,omitempty"`
+	ProjectUri string `json:"projectUri,omitempty"`
+}
+
+type jsonChecksum struct {
+	Algo  string `json:"algorithm"`
+	Value string `json:"checksumValue"`
+}
+
+type jsonReview struct {
+	Reviewer string `json:"reviewer,omitempty"`
+	Date     string `json:"reviewDate,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type jsonRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// Writes doc to w in the SPDX JSON format. Licences are written as licence
+// expressions (see FormatLicenceExpression()). Packages and files don't have
+// an SPDX identifier in spdx.Document, so they get the identifiers
+// "SPDXRef-Package-N" and "SPDXRef-File-N" in the order they appear in doc;
+// files are all listed at the top level and referenced by the packages that
+// contain them.
+func WriteJSON(w io.Writer, doc *spdx.Document) error {
+	jw := &jsonWriter{ids: make(map[interface{}]string)}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jw.document(doc))
+}
+
+// Converts documents to jsonDocument, keeping the identifiers given to the
+// elements.
+type jsonWriter struct {
+	ids map[interface{}]string
+}
+
+func (jw *jsonWriter) document(doc *spdx.Document) *jsonDocument {
+	jd := &jsonDocument{
+		Id:          doc.Id.Val,
+		SpecVersion: doc.SpecVersion.Val,
+		Namespace:   doc.Namespace.Val,
+		Comment:     doc.Comment.Val,
+	}
+	if jd.Id == "" {
+		jd.Id = "SPDXRef-DOCUMENT"
+	}
+	jw.ids[doc] = jd.Id
+	if lic := doc.DataLicenceLicence(); lic != nil {
+		jd.DataLicence = lic.LicenceId()
+	}
+	if ci := doc.CreationInfo; ci != nil {
+		jd.CreationInfo = &jsonCreationInfo{
+			Created:            ci.Created.V(),
+			LicenceListVersion: ci.LicenceListVersion.Val,
+			Comment:            ci.Comment.Val,
+		}
+		for _, creator := range ci.Creator {
+			jd.CreationInfo.Creators = append(jd.CreationInfo.Creators, creator.V())
+		}
+	}
+	for _, ref := range doc.ExternalDocumentRefs {
+		jd.ExternalDocumentRefs = append(jd.ExternalDocumentRefs, &jsonExternalDocRef{
+			Id:       ref.Id.Val,
+			Document: ref.Document.Val,
+			Checksum: jsonChecksumOf(ref.Checksum),
+		})
+	}
+	for _, lic := range doc.ExtractedLicences {
+		jd.ExtractedLicences = append(jd.ExtractedLicences, &jsonExtractedLicence{
+			Id:      lic.Id.Val,
+			Text:    lic.Text.Val,
+			Name:    spdx.Join(lic.Name, ", "),
+			SeeAlso: jsonStrings(lic.CrossReference),
+			Comment: lic.Comment.Val,
+		})
+	}
+
+	// identifiers first, so that relationships can refer to any element
+	var files []*spdx.File
+	for _, file := range doc.Files {
+		files = jw.addFile(files, file)
+	}
+	for i, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		jw.ids[pkg] = fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		for _, file := range pkg.Files {
+			files = jw.addFile(files, file)
+		}
+	}
+
+	for _, pkg := range doc.Packages {
+		if pkg != nil {
+			jd.Packages = append(jd.Packages, jw.pkg(pkg))
+			jd.Relationships = append(jd.Relationships, jw.relationships(pkg, pkg.Relationships)...)
+		}
+	}
+	for _, file := range files {
+		jd.Files = append(jd.Files, jw.file(file))
+		jd.Relationships = append(jd.Relationships, jw.relationships(file, file.Relationships)...)
+	}
+	for _, rev := range doc.Reviews {
+		jd.Reviews = append(jd.Reviews, &jsonReview{
+			Reviewer: rev.Reviewer.V(),
+			Date:     rev.Date.V(),
+			Comment:  rev.Comment.Val,
+		})
+	}
+	jd.Relationships = append(jw.relationships(doc, doc.Relationships), jd.Relationships...)
+	return jd
+}
+
+// Gives an identifier to file and appends it to files, unless it already has
+// one.
+func (jw *jsonWriter) addFile(files []*spdx.File, file *spdx.File) []*spdx.File {
+	if file == nil || jw.ids[file] != "" {
+		return files
+	}
+	files = append(files, file)
+	jw.ids[file] = fmt.Sprintf("SPDXRef-File-%d", len(files))
+	return files
+}
+
+func (jw *jsonWriter) pkg(pkg *spdx.Package) *jsonPackage {
+	jp := &jsonPackage{
+		Id:                   jw.ids[pkg],
+		Name:                 pkg.Name.Val,
+		Version:              pkg.Version.Val,
+		FileName:             pkg.FileName.Val,
+		Supplier:             pkg.Supplier.V(),
+		Originator:           pkg.Originator.V(),
+		DownloadLocation:     pkg.DownloadLocation.Val,
+		HomePage:             pkg.HomePage.Val,
+		SourceInfo:           pkg.SourceInfo.Val,
+		LicenceConcluded:     FormatLicenceExpression(pkg.LicenceConcluded),
+		LicenceDeclared:      FormatLicenceExpression(pkg.LicenceDeclared),
+		LicenceInfoFromFiles: jsonLicences(pkg.LicenceInfoFromFiles),
+		LicenceComments:      pkg.LicenceComments.Val,
+		CopyrightText:        pkg.CopyrightText.Val,
+		Summary:              pkg.Summary.Val,
+		Description:          pkg.Description.Val,
+	}
+	if cksum := jsonChecksumOf(pkg.Checksum); cksum != nil {
+		jp.Checksums = []*jsonChecksum{cksum}
+	}
+	if vc := pkg.VerificationCode; vc != nil {
+		jp.VerificationCode = &jsonVerificationCode{
+			Value:         vc.Value.Val,
+			ExcludedFiles: jsonStrings(vc.ExcludedFiles),
+		}
+	}
+	for _, ref := range pkg.ExternalRefs {
+		jp.ExternalRefs = append(jp.ExternalRefs, &jsonExternalRef{
+			Category: ref.Category.Val,
+			Type:     ref.Type.Val,
+			Locator:  ref.Locator.Val,
+			Comment:  ref.Comment.Val,
+		})
+	}
+	for _, file := range pkg.Files {
+		if file != nil {
+			jp.HasFiles = append(jp.HasFiles, jw.ids[file])
+		}
+	}
+	return jp
+}
+
+func (jw *jsonWriter) file(file *spdx.File) *jsonFile {
+	jf := &jsonFile{
+		Id:                jw.ids[file],
+		Name:              file.Name.Val,
+		LicenceConcluded:  FormatLicenceExpression(file.LicenceConcluded),
+		LicenceInfoInFile: jsonLicences(file.LicenceInfoInFile),
+		LicenceComments:   file.LicenceComments.Val,
+		CopyrightText:     file.CopyrightText.Val,
+		Notice:            file.Notice.Val,
+		Contributors:      jsonStrings(file.Contributor),
+		Comment:           file.Comment.Val,
+	}
+	if file.Type.Val != "" {
+		jf.Types = append([]string{file.Type.Val}, jsonStrings(file.ExtraTypes)...)
+	}
+	if cksum := jsonChecksumOf(file.Checksum); cksum != nil {
+		jf.Checksums = []*jsonChecksum{cksum}
+	}
+	for _, artif := range file.ArtifactOf {
+		jf.ArtifactOf = append(jf.ArtifactOf, &jsonArtifactOf{
+			Name:       artif.Name.Val,
+			HomePage:   artif.HomePage.Val,
+			ProjectUri: artif.ProjectUri.Val,
+		})
+	}
+	return jf
+}
+
+// Converts the relationships of el. Related elements written in the document
+// are referred to by their identifier, others by the identifier in their URI.
+func (jw *jsonWriter) relationships(el interface{}, rels []*spdx.Relationship) []*jsonRelationship {
+	var res []*jsonRelationship
+	for _, rel := range rels {
+		related := jw.ids[rel.Element]
+		if related == "" {
+			related = nodeId(uri(rel.Related.Val))
+		}
+		if related == "" {
+			related = rel.Related.Val
+		}
+		res = append(res, &jsonRelationship{
+			Element: jw.ids[el],
+			Type:    rel.Type.Val,
+			Related: related,
+			Comment: rel.Comment.Val,
+		})
+	}
+	return res
+}
+
+func jsonChecksumOf(cksum *spdx.Checksum) *jsonChecksum {
+	if cksum == nil || cksum.Value.Val == "" {
+		return nil
+	}
+	return &jsonChecksum{Algo: cksum.Algo.Val, Value: cksum.Value.Val}
+}
+
+func jsonLicences(lics []spdx.AnyLicence) []string {
+	var res []string
+	for _, lic := range lics {
+		res = append(res, FormatLicenceExpression(lic))
+	}
+	return res
+}
+
+func jsonStrings(values []spdx.ValueStr) []string {
+	var res []string
+	for _, v := range values {
+		res = append(res, v.Val)
+	}
+	return res
+}
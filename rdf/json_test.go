@@ -0,0 +1,76 @@
+package rdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/vladvelici/spdx-go/spdx"
+	"reflect"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	file := &spdx.File{
+		Name:              spdx.Str("./main.go", nil),
+		Type:              spdx.Str("SOURCE", nil),
+		Checksum:          &spdx.Checksum{Algo: spdx.Str("SHA1", nil), Value: spdx.Str("2fd4e1c67a2d28fced849ee1bb76e7391b93eb12", nil)},
+		LicenceConcluded:  spdx.NewLicence("MIT", nil),
+		LicenceInfoInFile: []spdx.AnyLicence{spdx.NewLicence("MIT", nil)},
+	}
+	pkg := &spdx.Package{
+		Name:             spdx.Str("spdx-go", nil),
+		LicenceConcluded: spdx.NewDisjunctiveSet(nil, spdx.NewLicence("MIT", nil), spdx.NewConjunctiveSet(nil, spdx.NewLicence("ISC", nil), spdx.NewLicence("Zlib", nil))),
+		LicenceDeclared:  spdx.NewLicence("MIT", nil),
+		Files:            []*spdx.File{file},
+	}
+	pkg.Relationships = []*spdx.Relationship{{
+		Type:    spdx.Str("CONTAINS", nil),
+		Related: spdx.Str("http://example.org/doc#SPDXRef-main", nil),
+		Element: file,
+	}}
+	doc := &spdx.Document{
+		SpecVersion: spdx.Str("SPDX-1.2", nil),
+		DataLicence: spdx.Str(spdx.DATA_LICENCE_RDF, nil),
+		CreationInfo: &spdx.CreationInfo{
+			Creator: []spdx.ValueCreator{spdx.NewValueCreator("Tool: spdx-go", nil)},
+			Created: spdx.NewValueDate("2014-08-06T12:00:00Z", nil),
+		},
+		Packages: []*spdx.Package{pkg},
+		Reviews:  []*spdx.Review{{Reviewer: spdx.NewValueCreator("Person: Jane", nil)}},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WriteJSON(buf, doc); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var found map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &found); err != nil {
+		t.Fatalf("Invalid JSON: %s\n%s", err, buf)
+	}
+	var expected map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"spdxVersion": "SPDX-1.2",
+		"dataLicense": "CC0-1.0",
+		"creationInfo": {"created": "2014-08-06T12:00:00Z", "creators": ["Tool: spdx-go"]},
+		"packages": [{
+			"SPDXID": "SPDXRef-Package-1",
+			"name": "spdx-go",
+			"licenseConcluded": "MIT OR ISC AND Zlib",
+			"licenseDeclared": "MIT",
+			"hasFiles": ["SPDXRef-File-1"]
+		}],
+		"files": [{
+			"SPDXID": "SPDXRef-File-1",
+			"fileName": "./main.go",
+			"fileTypes": ["SOURCE"],
+			"checksums": [{"algorithm": "SHA1", "checksumValue": "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12"}],
+			"licenseConcluded": "MIT",
+			"licenseInfoInFiles": ["MIT"]
+		}],
+		"reviewers": [{"reviewer": "Person: Jane"}],
+		"relationships": [{"spdxElementId": "SPDXRef-Package-1", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-File-1"}]
+	}`), &expected)
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Wrong JSON:\n%s", buf)
+	}
+}
@@ -1,39 +1,89 @@
 package rdf
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/vladvelici/goraptor"
 	"github.com/vladvelici/spdx-go/spdx"
 	"io"
+	"net/url"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // RDF element types in URI format. (RDF classes).
 var (
 	uri_nstype = uri("http://www.w3.org/1999/02/22-rdf-syntax-ns#type")
 
-	typeDocument           = prefix("SpdxDocument")
-	typeCreationInfo       = prefix("CreationInfo")
-	typePackage            = prefix("Package")
-	typeFile               = prefix("File")
-	typeVerificationCode   = prefix("PackageVerificationCode")
-	typeChecksum           = prefix("Checksum")
-	typeArtifactOf         = prefix("doap:Project")
-	typeReview             = prefix("Review")
-	typeExtractedLicence   = prefix("ExtractedLicensingInfo")
-	typeAnyLicence         = prefix("AnyLicenseInfo")
-	typeConjunctiveSet     = prefix("ConjunctiveLicenseSet")
-	typeDisjunctiveSet     = prefix("DisjunctiveLicenseSet")
-	typeLicence            = prefix("License")
-	typeAbstractLicenceSet = blank("abstractLicenceSet")
+	typeDocument            = prefix("SpdxDocument")
+	typeCreationInfo        = prefix("CreationInfo")
+	typePackage             = prefix("Package")
+	typeFile                = prefix("File")
+	typeVerificationCode    = prefix("PackageVerificationCode")
+	typeChecksum            = prefix("Checksum")
+	typeArtifactOf          = prefix("doap:Project")
+	typeReview              = prefix("Review")
+	typeExtractedLicence    = prefix("ExtractedLicensingInfo")
+	typeAnyLicence          = prefix("AnyLicenseInfo")
+	typeConjunctiveSet      = prefix("ConjunctiveLicenseSet")
+	typeDisjunctiveSet      = prefix("DisjunctiveLicenseSet")
+	typeLicence             = prefix("License")
+	typeLicenceException    = prefix("LicenseException")
+	typeWithException       = prefix("WithExceptionOperator")
+	typeExternalDocumentRef = prefix("ExternalDocumentRef")
+	typeRelationship        = prefix("Relationship")
+	typeExternalRef         = prefix("ExternalRef")
+	typeAnnotation          = prefix("Annotation")
+	typeSnippet             = prefix("Snippet")
+	typeStartEndPointer     = prefix("ptr:StartEndPointer")
+	typeSinglePointer       = prefix("ptr:SinglePointer")
+	typeByteOffsetPointer   = prefix("ptr:ByteOffsetPointer")
+	typeLineCharPointer     = prefix("ptr:LineCharPointer")
+	typeAbstractLicenceSet  = blank("abstractLicenceSet")
+	typeNestedValue         = blank("nestedValue")
 )
 
 // Common RDF parser error messages.
 const (
 	msgIncompatibleTypes    = "%s is already set to be type %s and cannot be changed to type %s."
+	msgIncompatibleTypesAt  = "%s is already set to be type %s at line %d and cannot be changed to type %s."
 	msgPropertyNotSupported = "Property %s is not supported for %s."
 	msgAlreadyDefined       = "Property already defined."
-	msgUnknownType          = "Found type %s which is unknown."
+	msgUnknownType          = "Found type %s for %s which is unknown."
+	msgUnknownTypeLine      = "Found type %s for %s at line %d which is unknown."
+	msgDuplicateId          = "SPDX identifier %s is already used at line %d."
+	msgDuplicateIdNoMeta    = "SPDX identifier %s is already used."
+	msgTrailingContent      = "Unexpected content after the end of the RDF document."
+	msgVerifCodeAlgorithm   = "Package verification code algorithm must be SHA1, found %s."
+	msgTooManyUnknown       = "Found %d unsupported and %d supported properties. The input is likely not a SPDX document."
+	msgRelationshipType     = "Unknown relationship type %s."
+	msgReferenceCategory    = "Unknown external reference category %s."
+	msgPrimaryPurpose       = "Unknown primary package purpose %s."
+	msgAnnotationType       = "Annotation type must be REVIEW or OTHER, found %s."
+	msgTooManyMembers       = "Licence set has more than %d members."
+	msgTooManyBuffered      = "More than %d statements are waiting for the type of their subject."
+	msgUnsupportedFormat    = "Format %s is not supported for parsing. Supported formats are: %s."
+	msgRaptorInit           = "Raptor cannot create a parser for format %s."
+	msgFileVerification     = "File %s failed verification."
+	msgAbstractSet          = "Licence set %s has no concrete type (ConjunctiveLicenseSet or DisjunctiveLicenseSet)."
+	msgChecksumValue        = "Malformed checksum value %s for algorithm %s."
+	msgUnboundPrefix        = "Namespace prefix %s is used in %s but it is not bound."
+	msgFileType             = "Unknown file type %s."
+	msgDateFormat           = "Date must be in the format YYYY-MM-DDThh:mm:ssZ, found %s."
+	msgListVersion          = "Licence list version must be in the format M.N, found %s."
+	msgUndefinedFile        = "File %s is referenced by %s but it is never defined."
+	msgDeprecatedProperty   = "Property %s of %s is deprecated."
+	msgFilesAnalyzed        = "filesAnalyzed must be true or false, found %s."
+	msgInputNotRead         = "The RDF parser stopped before the end of the input, which is likely malformed."
+	msgReadError            = "Cannot read the input: %s."
+	msgUnclosedRDF          = "The input ends before the end of the RDF/XML root element."
+	msgObsoleteNamespace    = "The document uses the obsolete terms namespace " + obsoleteBaseUri + "."
+	msgInlinedCreationInfo  = "Creation information is both inlined on the document and in a CreationInfo node."
 )
 
 // Abstract licence set interface.
@@ -43,17 +93,145 @@ type abstractLicenceSet interface {
 
 // Simple, one function call interface to parse a document
 func Parse(input io.Reader, format string) (*spdx.Document, error) {
-	parser := NewParser(input, format)
+	parser, err := NewParser(input, format)
+	if err != nil {
+		return nil, err
+	}
+	defer parser.Free()
+	return parser.Parse()
+}
+
+// Parses all the inputs concurrently, with at most runtime.NumCPU() inputs
+// being parsed at the same time. The document and the error returned for
+// every input are at the same index as the input.
+func ParseConcurrent(inputs []io.Reader, format string) ([]*spdx.Document, []error) {
+	docs := make([]*spdx.Document, len(inputs))
+	errs := make([]error, len(inputs))
+	workers := runtime.NumCPU()
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				docs[i], errs[i] = Parse(inputs[i], format)
+			}
+		}()
+	}
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return docs, errs
+}
+
+// Guards the creation and destruction of goraptor parsers: raptor
+// initialises global state (libxml2) when a parser is created, which isn't
+// safe to do from several goroutines at once. Parsing itself is independent
+// for each Parser.
+var raptorMu sync.Mutex
+
+// Parses only the header of a document: its top-level properties and its
+// creation info. Packages, files and licences are not built. The whole input
+// is still read since RDF gives no guarantee that the header comes first.
+func ParseHeader(input io.Reader, format string) (*spdx.Document, error) {
+	parser, err := NewParser(input, format)
+	if err != nil {
+		return nil, err
+	}
 	defer parser.Free()
+	parser.headerOnly = true
 	return parser.Parse()
 }
 
+// Properties of a document kept by ParseHeader.
+var headerProperties = map[string]bool{
+	"specVersion":        true,
+	"dataLicense":        true,
+	"rdfs:comment":       true,
+	"creationInfo":       true,
+	"creator":            true,
+	"created":            true,
+	"licenseListVersion": true,
+}
+
+// Checks whether stm is part of the document header, see ParseHeader(). Nodes
+// of other types are recorded in p.skipped so that their properties are
+// ignored too.
+func (p *Parser) inHeader(stm *goraptor.Statement) bool {
+	node := termStr(stm.Subject)
+	if p.skipped[node] {
+		return false
+	}
+	if stm.Predicate.Equals(uri_nstype) {
+		if equalTypes(stm.Object, typeDocument, typeCreationInfo) {
+			return true
+		}
+		p.skip(node)
+		return false
+	}
+	bldr, ok := p.index[node]
+	if !ok {
+		// the type of the node is not known yet
+		return true
+	}
+	if bldr.t.Equals(typeDocument) {
+		return headerProperties[shortPrefix(stm.Predicate)]
+	}
+	return bldr.t.Equals(typeCreationInfo)
+}
+
+// Predicates linking a document or a package to its files.
+var fileProperties = map[string]bool{
+	"referencesFile":  true,
+	"hasFile":         true,
+	"snippetFromFile": true,
+	"ptr:reference":   true,
+}
+
+// Checks whether stm is to be ignored because of SkipFiles: statements about
+// files and the ones linking to files. Nodes typed as files are recorded in
+// p.skipped so that their properties are ignored too.
+func (p *Parser) skipFile(stm *goraptor.Statement) bool {
+	node := termStr(stm.Subject)
+	if p.skipped[node] {
+		return true
+	}
+	if stm.Predicate.Equals(uri_nstype) {
+		if equalTypes(stm.Object, typeFile) {
+			p.skip(node)
+			return true
+		}
+		return false
+	}
+	if fileProperties[shortPrefix(stm.Predicate)] {
+		p.skip(termStr(stm.Object))
+		return true
+	}
+	return false
+}
+
+// Records node as skipped and drops the statements buffered for it.
+func (p *Parser) skip(node string) {
+	if p.skipped == nil {
+		p.skipped = make(map[string]bool)
+	}
+	p.skipped[node] = true
+	p.buffered -= len(p.buffer[node])
+	delete(p.buffer, node)
+}
+
 // Update a ValString pointer
 func upd(ptr *spdx.ValueStr) updater {
 	set := false
 	return func(term goraptor.Term, meta *spdx.Meta) error {
 		if set {
-			return spdx.NewParseError(msgAlreadyDefined, meta)
+			return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
 		}
 
 		ptr.Val = termStr(term)
@@ -68,7 +246,7 @@ func updCutPrefix(prefix string, ptr *spdx.ValueStr) updater {
 	set := false
 	return func(term goraptor.Term, meta *spdx.Meta) error {
 		if set {
-			return spdx.NewParseError(msgAlreadyDefined, meta)
+			return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
 		}
 
 		ptr.Val = strings.TrimPrefix(termStr(term), prefix)
@@ -78,6 +256,22 @@ func updCutPrefix(prefix string, ptr *spdx.ValueStr) updater {
 	}
 }
 
+// Updates a ValueStr pointer with free text. Literals are kept verbatim,
+// including their line breaks, and the spdx:noassertion and spdx:none
+// resources are stored as NOASSERTION and NONE.
+func updText(ptr *spdx.ValueStr) updater {
+	set := upd(ptr)
+	return func(term goraptor.Term, meta *spdx.Meta) error {
+		switch {
+		case term.Equals(prefix("noassertion")):
+			term = literal(spdx.NOASSERTION)
+		case term.Equals(prefix("none")):
+			term = literal(spdx.NONE)
+		}
+		return set(term, meta)
+	}
+}
+
 // Update a []ValString pointer
 func updList(arr *[]spdx.ValueStr) updater {
 	return func(term goraptor.Term, meta *spdx.Meta) error {
@@ -91,7 +285,7 @@ func updCreator(ptr *spdx.ValueCreator) updater {
 	set := false
 	return func(term goraptor.Term, meta *spdx.Meta) error {
 		if set {
-			return spdx.NewParseError(msgAlreadyDefined, meta)
+			return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
 		}
 		ptr.SetValue(termStr(term))
 		ptr.Meta = meta
@@ -100,20 +294,48 @@ func updCreator(ptr *spdx.ValueCreator) updater {
 	}
 }
 
-// Update a ValueDate pointer
+// Update a ValueDate pointer. The date must be in the UTC format required by
+// SPDX (YYYY-MM-DDThh:mm:ssZ).
 func updDate(ptr *spdx.ValueDate) updater {
 	set := false
 	return func(term goraptor.Term, meta *spdx.Meta) error {
 		if set {
-			return spdx.NewParseError(msgAlreadyDefined, meta)
+			return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
 		}
-		ptr.SetValue(termStr(term))
+		val := termStr(term)
+		if _, err := time.Parse(dateFormat, val); err != nil {
+			return spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgDateFormat, val), meta)
+		}
+		ptr.SetValue(val)
 		ptr.Meta = meta
 		set = true
 		return nil
 	}
 }
 
+// Update a copyright text and its sentinel. The NOASSERTION and NONE values
+// are read from both literals and the spdx:noassertion and spdx:none
+// resources, the text is then "NOASSERTION" or "NONE".
+func updCopyright(ptr *spdx.ValueStr, sentinel *spdx.Sentinel) updater {
+	set := false
+	return func(term goraptor.Term, meta *spdx.Meta) error {
+		if set {
+			return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+		}
+		val := termStr(term)
+		switch val {
+		case termStr(prefix("noassertion")):
+			val = spdx.NOASSERTION
+		case termStr(prefix("none")):
+			val = spdx.NONE
+		}
+		*ptr = spdx.Str(val, meta)
+		*sentinel = spdx.SentinelOf(val)
+		set = true
+		return nil
+	}
+}
+
 // Update a []ValueCreator pointer
 func updListCreator(arr *[]spdx.ValueCreator) updater {
 	return func(term goraptor.Term, meta *spdx.Meta) error {
@@ -123,18 +345,49 @@ func updListCreator(arr *[]spdx.ValueCreator) updater {
 }
 
 type builder struct {
-	t        goraptor.Term // type of element this builder represents
-	ptr      interface{}   // the spdx element that this builder builds
-	updaters map[string]updater
+	t          goraptor.Term // type of element this builder represents
+	ptr        interface{}   // the spdx element that this builder builds
+	meta       *spdx.Meta    // where the type was set, nil if the node was only referenced
+	updaters   map[string]updater
+	deprecated map[string]bool // properties reported as deprecated when applied
+
+	// if set, applied to the properties that have no updater
+	other func(pred, obj goraptor.Term, meta *spdx.Meta) error
+}
+
+// Marks properties of b as deprecated: the parser collects a warning every
+// time one of them is applied.
+func (b *builder) deprecate(properties ...string) {
+	if b.deprecated == nil {
+		b.deprecated = make(map[string]bool)
+	}
+	for _, property := range properties {
+		b.deprecated[property] = true
+	}
 }
 
 func (b *builder) apply(pred, obj goraptor.Term, meta *spdx.Meta) error {
 	property := shortPrefix(pred)
 	f, ok := b.updaters[property]
-	if !ok {
-		return spdx.NewParseError(fmt.Sprintf(msgPropertyNotSupported, property, b.t), meta)
+	if !ok && b.other == nil {
+		return spdx.NewParseErrorCode(spdx.ErrPropertyNotSupported, fmt.Sprintf(msgPropertyNotSupported, property, b.t), meta)
 	}
-	return f(obj, meta)
+	var err error
+	if ok {
+		err = f(obj, meta)
+	} else {
+		err = b.other(pred, obj, meta)
+	}
+	if err != nil {
+		return err
+	}
+	// extend the line range of the element to this property
+	if el, ok := b.ptr.(interface {
+		M() *spdx.Meta
+	}); ok && el.M() != nil && meta != nil && meta.LineEnd > el.M().LineEnd {
+		el.M().LineEnd = meta.LineEnd
+	}
+	return nil
 }
 
 func (b *builder) has(pred string) bool {
@@ -142,8 +395,23 @@ func (b *builder) has(pred string) bool {
 	return ok
 }
 
+// Returns the property of b equal to pred under case folding, or the empty
+// string if there is none.
+func (b *builder) fold(pred string) string {
+	for property := range b.updaters {
+		if strings.EqualFold(property, pred) {
+			return property
+		}
+	}
+	return ""
+}
+
 type updater func(goraptor.Term, *spdx.Meta) error
 
+// Applies the object obj of a statement, read at meta, to an element
+// registered with RegisterType() or RegisterProperty().
+type Updater func(obj goraptor.Term, meta *spdx.Meta) error
+
 type bufferEntry struct {
 	*goraptor.Statement
 	*spdx.Meta
@@ -154,37 +422,368 @@ type bufferEntry struct {
 //
 // Always use the `NewParser()` method to create a new parser.
 type Parser struct {
+	// If Strict is set, recoverable problems in the input are returned as
+	// errors. Otherwise they are collected as warnings (see Warnings()) and
+	// parsing continues. NewParser() sets Strict to true.
+	Strict bool
+
+	// If MaxUnknownRatio is greater than 0 and, after parsing leniently, the
+	// ratio of unsupported to supported properties is greater than it, Parse
+	// returns an error: the input is likely not a SPDX document.
+	MaxUnknownRatio float64
+
+	// If InferTypes is set, nodes whose type is never declared get the type
+	// whose properties uniquely match the ones found for the node, at the end
+	// of the input. See inferTypes().
+	InferTypes bool
+
+	// If RetainLocators is set, the statements read from the input and their
+	// full positions (line, column, byte and file) are kept. See Statements()
+	// and Locators().
+	RetainLocators bool
+
+	// If DedupFiles is set, files of the document and its packages that have
+	// the same identity are merged into one *spdx.File after parsing. The
+	// identity is given by FileIdentity, or DefaultFileIdentity if it is nil.
+	DedupFiles   bool
+	FileIdentity func(*spdx.File) string
+
+	// If OnFileVerify is set, it is called once for every file of the input,
+	// in order, when the file is known to be complete. RDF doesn't guarantee
+	// that the properties of a file (or of its checksum) are contiguous, so
+	// the files of a document are verified when the next document starts, for
+	// input with several documents (see ParseMany), and the files of the last
+	// document after the input has been read. Verified files are no longer
+	// kept for verification. A file for which OnFileVerify returns false is
+	// reported as an error in Strict mode and as a warning otherwise. An error
+	// returned by OnFileVerify stops the parsing and is returned by Parse.
+	OnFileVerify func(*spdx.File) (bool, error)
+
+	// If KeepUnknownTypes is set, the nodes of unknown types are not errors:
+	// they are kept with all their properties in the Unknown elements of the
+	// document, see spdx.UnknownElement.
+	KeepUnknownTypes bool
+
+	// If MaxSetMembers is greater than 0, licence sets with more members are
+	// rejected with an error.
+	MaxSetMembers int
+
+	// If DedupSetMembers is set, a member listed more than once in a licence
+	// set is kept only once: the same node, or a listed licence with the same
+	// identifier. A set such as (MIT and MIT) is then read as (MIT). The
+	// dropped members don't count against MaxSetMembers.
+	DedupSetMembers bool
+
+	// If MaxBuffered is greater than 0, Parse returns an error when more
+	// statements are waiting for the type of their subject, which bounds the
+	// memory used by inputs whose nodes are never typed. NewParser() sets it
+	// to DefaultMaxBuffered.
+	MaxBuffered int
+
+	// If CanonicalizeLicences is set, the licences of the packages and files
+	// are rewritten to their canonical form after parsing, see
+	// spdx.CanonicalLicence(). Equivalent licence expressions are then equal.
+	CanonicalizeLicences bool
+
+	// If Trace is set, every statement read is written to it in N-Triples
+	// form, with its line, before being processed. Write errors are ignored.
+	Trace io.Writer
+
+	// If SkipFiles is set, files are not built: the nodes typed as files,
+	// their properties and the referencesFile and hasFile properties are
+	// ignored. Packages are still fully built, except for their files.
+	SkipFiles bool
+
+	// If NormalizeFileNames is set, file names are read as SPDX file names:
+	// with forward slashes and starting with "./", see
+	// spdx.NormalizeFileName(). The names as found in the input are kept in
+	// OriginalName.
+	NormalizeFileNames bool
+
+	// If CaseInsensitiveProperties is set, properties that aren't supported
+	// for a type but differ only in case from a supported one (such as
+	// "rdfs:Comment") are read as the supported property.
+	CaseInsensitiveProperties bool
+
+	// If ReturnPartial is set, Parse and ParseMany return the documents as
+	// built until the first error along with it: the nodes processed before
+	// the failing statement are in them, which helps to diagnose the error.
+	// Otherwise no document is returned with an error. NewParser() sets
+	// ReturnPartial to true.
+	ReturnPartial bool
+
+	// If OnType is set, it is called with the node and its type every time a
+	// type is assigned to a node or changed, including types inferred from
+	// references to the node.
+	OnType func(node string, t goraptor.Term)
+
+	// builder factories of the types registered with RegisterType(), by type
+	// URI
+	types map[string]func(meta *spdx.Meta) *builder
+
+	// updaters registered with RegisterProperty(), by type URI and property
+	properties map[string]map[string]updater
+
+	// namespaces added with AddNamespace(): the namespace each URI is an
+	// alias of
+	namespaces map[string]string
+
+	// number of statements read and the progress callback, see SetProgress()
+	read     int
+	progress func(statements int)
+
+	// statements and their positions, kept if RetainLocators is set
+	statements []*goraptor.Statement
+	locators   []goraptor.Locator
+
+	// number of supported and unsupported properties found
+	known, unknown int
+
+	// counts of the statements processed and of the elements built
+	stats ParseStats
+
 	rdfparser *goraptor.Parser
 	input     io.Reader
 	index     map[string]*builder
 	buffer    map[string][]bufferEntry
-	doc       *spdx.Document
+	buffered  int              // number of statements in buffer
+	doc       *spdx.Document   // last document found
+	docs      []*spdx.Document // all the documents found
+	warnings  []*spdx.ParseError
+
+	// whether the obsolete terms namespace was found
+	obsolete bool
+
+	// unbound namespace prefixes found, see unboundPrefix()
+	unbound map[string]bool
+
+	// placeholders returned for licence sets referenced before their type
+	// is known, and their nodes, see resolveLicenceSets()
+	pendingSets map[*spdx.UnknownLicence]string
+
+	// relationships whose element is resolved at the end of the input
+	related []relatedElement
+
+	// files excluded from verification codes by reference
+	excluded []excludedFile
+
+	// references to files, checked at the end of the input
+	fileRefs []fileRef
+
+	// files not verified yet, in the order they were found, see OnFileVerify
+	files []*spdx.File
+
+	// elements of unknown types, see KeepUnknownTypes
+	unknownElements []*spdx.UnknownElement
+
+	// licences by ID, see internLicence()
+	licences map[string]*spdx.Licence
+
+	// order in which the nodes were first found as subjects, see
+	// SortBySource()
+	seq map[string]int
+
+	// whether only the header is parsed and the nodes skipped, see
+	// ParseHeader(), also used for the files skipped with SkipFiles
+	headerOnly bool
+	skipped    map[string]bool
+
+	// SPDX identifiers seen so far in the current document, see defineId()
+	ids map[string]idDefinition
+
+	// wraps input for RDF/XML formats to detect content after the root element
+	trailer *trailingReader
+
+	// wraps the input given to goraptor to detect when it stops reading
+	// before the end, see checkEnd()
+	end *endReader
+
+	// line of the last statement read
+	line int
+
+	// error found when creating the parser, returned by Parse
+	err error
+}
+
+// Parses a document leniently and computes its conformance score (see
+// spdx.ScoreDocument), counting the warnings found while parsing.
+func ParseWithScore(input io.Reader, format string) (*spdx.Document, spdx.ConformanceScore, error) {
+	parser, err := NewParser(input, format)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer parser.Free()
+	parser.Strict = false
+	doc, err := parser.Parse()
+	if err != nil {
+		return doc, 0, err
+	}
+	return doc, spdx.ScoreDocument(doc, len(parser.Warnings())), nil
 }
 
 // This creates a goraptor.Parser object that needs to be freed after use.
 // Call Parser.Free() after using the Parser.
-func NewParser(input io.Reader, format string) *Parser {
-	if format == "rdf" {
-		format = "guess"
+//
+// The format must be one of SupportedFormats(). "rdf" is accepted as an alias
+// of FormatGuess. If the format is not supported or raptor cannot create a
+// parser for it, an *InitError is returned.
+func NewParser(input io.Reader, format string) (*Parser, error) {
+	p := &Parser{Strict: true, MaxBuffered: DefaultMaxBuffered, ReturnPartial: true}
+	if err := p.init(input, format); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Default value of Parser.MaxBuffered. Documents usually type their nodes
+// before or shortly after their properties, so few statements are buffered.
+const DefaultMaxBuffered = 1000000
+
+// Like NewParser but panics if the parser cannot be created. Kept for code
+// written for the old NewParser, which didn't return an error.
+func MustNewParser(input io.Reader, format string) *Parser {
+	p, err := NewParser(input, format)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Error returned when a parser cannot be created for a format.
+type InitError struct {
+	Format string // The format requested.
+	msg    string
+}
+
+func (e *InitError) Error() string { return e.msg }
+
+// Clears the state of the parser and sets it up to parse a new document from
+// input, so that the Parser can be reused. If the parser cannot be created for
+// format, the error is returned by Parse(). The options (Strict,
+// MaxUnknownRatio, etc.) are kept. The underlying goraptor.Parser is freed and
+// a new one is created: Free() must still be called after the Parser is last
+// used.
+func (p *Parser) Reset(input io.Reader, format string) {
+	if p.rdfparser != nil {
+		raptorMu.Lock()
+		p.rdfparser.Free()
+		raptorMu.Unlock()
+		p.rdfparser = nil
+	}
+	p.known, p.unknown = 0, 0
+	p.doc, p.docs = nil, nil
+	p.warnings = nil
+	p.obsolete = false
+	p.unbound = nil
+	p.trailer = nil
+	p.end = nil
+	p.line = 0
+	p.statements, p.locators = nil, nil
+	p.read = 0
+	p.stats = ParseStats{}
+	p.related = nil
+	p.excluded = nil
+	p.fileRefs = nil
+	p.pendingSets = nil
+	p.licences = nil
+	p.files = nil
+	p.unknownElements = nil
+	p.skipped = nil
+	p.seq = nil
+	p.init(input, format)
+}
+
+// Creates the goraptor.Parser and the maps used while parsing. The error
+// returned, if any, is also kept to be returned by Parse().
+func (p *Parser) init(input io.Reader, format string) error {
+	p.input = input
+	p.index = make(map[string]*builder)
+	p.buffer = make(map[string][]bufferEntry)
+	p.buffered = 0
+	p.ids = make(map[string]idDefinition)
+	p.err = nil
+
+	name, err := parserFormat(format)
+	if err != nil {
+		p.err = err
+		return err
+	}
+	raptorMu.Lock()
+	p.rdfparser = goraptor.NewParser(name)
+	raptorMu.Unlock()
+	if p.rdfparser == nil {
+		p.err = &InitError{format, fmt.Sprintf(msgRaptorInit, format)}
+		return p.err
 	}
+	switch name {
+	case FormatGuess, FormatRDFXML:
+		p.trailer = &trailingReader{r: input}
+		p.input = p.trailer
+	}
+	p.end = &endReader{r: p.input, src: input}
+	p.input = p.end
+	return nil
+}
 
-	return &Parser{
-		rdfparser: goraptor.NewParser(format),
-		input:     input,
-		index:     make(map[string]*builder),
-		buffer:    make(map[string][]bufferEntry),
+// Returns the raptor parser name for format, or an *InitError if format is not
+// supported. The RDF/XML serializer formats are read with the RDF/XML parser.
+func parserFormat(format string) (string, error) {
+	switch format {
+	case "rdf":
+		return FormatGuess, nil
+	case Fmt_rdfxmlAbbrev, Fmt_rdfxmlXmp:
+		return FormatRDFXML, nil
+	}
+	for _, f := range SupportedFormats() {
+		if format == f {
+			return format, nil
+		}
 	}
+	return "", &InitError{format, fmt.Sprintf(msgUnsupportedFormat, format, strings.Join(SupportedFormats(), ", "))}
 }
 
 // Parse the whole input stream and return the resulting spdx.Document or the first error that occurred.
+// If ReturnPartial is set, the document built until the error is returned with
+// it.
 func (p *Parser) Parse() (*spdx.Document, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if err := p.parse(); err != nil {
+		if !p.ReturnPartial {
+			return nil, err
+		}
+		return p.doc, err
+	}
+	return p.doc, nil
+}
+
+// Parse the whole input stream and return all the SPDX documents found in it,
+// in order. Each document has the packages, files, etc. linked to its own
+// node. If ReturnPartial is set, the documents parsed until the first error
+// are returned with it.
+func (p *Parser) ParseMany() ([]*spdx.Document, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if err := p.parse(); err != nil {
+		if !p.ReturnPartial {
+			return nil, err
+		}
+		return p.docs, err
+	}
+	return p.docs, nil
+}
+
+// Read the input and build the documents.
+func (p *Parser) parse() error {
 	ch := p.rdfparser.Parse(p.input, baseUri)
 	locCh := p.rdfparser.LocatorChan()
 	var err error
 	for statement := range ch {
-		locator := <-locCh
-		meta := spdx.NewMetaL(locator.Line)
-		if err = p.processTruple(statement, meta); err != nil {
+		if err = p.readStatement(statement, <-locCh); err != nil {
+			// goraptor stops at the next read instead of parsing the rest
+			p.end.stop()
 			break
 		}
 	}
@@ -192,15 +791,330 @@ func (p *Parser) Parse() (*spdx.Document, error) {
 	for _ = range ch {
 		<-locCh
 	}
-	return p.doc, err
+	p.end.close()
+	if err == nil {
+		err = p.checkEnd()
+	}
+	if err == nil && p.progress != nil {
+		p.progress(p.read)
+	}
+	if err == nil && p.InferTypes {
+		err = p.inferTypes()
+	}
+	if err == nil {
+		err = p.checkAbstractSets()
+	}
+	if err == nil {
+		p.assignLicenseRefs()
+		p.resolveLicenceSets()
+		p.resolveRelated()
+		p.resolveExcludedFiles()
+		p.checkFileRefs()
+		if p.doc != nil {
+			p.doc.Unknown = append(p.doc.Unknown, p.unknownElements...)
+		}
+	}
+	if err == nil && p.DedupFiles {
+		p.dedupFiles()
+	}
+	if err == nil && p.CanonicalizeLicences {
+		for _, doc := range p.docs {
+			doc.CanonicalizeLicences()
+		}
+	}
+	if err == nil && p.OnFileVerify != nil {
+		err = p.verifyFiles()
+	}
+	if err == nil {
+		err = p.checkUnknownRatio()
+	}
+	if p.trailer != nil && p.trailer.trailing {
+		perr := spdx.NewParseErrorCode(spdx.ErrTrailingContent, msgTrailingContent, spdx.NewMetaL(p.trailer.line))
+		if err == nil && p.Strict {
+			err = perr
+		} else {
+			p.warnings = append(p.warnings, perr)
+		}
+	}
+	return err
+}
+
+// Process a statement read from the input at the position given by locator.
+func (p *Parser) readStatement(stm *goraptor.Statement, locator *goraptor.Locator) error {
+	p.read++
+	p.line = locator.Line
+	if p.progress != nil && p.read%progressInterval == 0 {
+		p.progress(p.read)
+	}
+	if p.RetainLocators {
+		p.statements = append(p.statements, stm)
+		p.locators = append(p.locators, *locator)
+	}
+	return p.processTruple(stm, spdx.NewMetaL(locator.Line))
+}
+
+// Number of statements between two calls of the progress callback.
+const progressInterval = 1000
+
+// Sets a function called with the number of statements processed so far every
+// progressInterval statements, and once more with the total when the input has
+// been read. fn is called on the parsing goroutine and should not block. A nil
+// fn removes the callback.
+func (p *Parser) SetProgress(fn func(statements int)) { p.progress = fn }
+
+// Returns the statements read from the input, in order, if RetainLocators is
+// set. The position of each statement is at the same index in Locators().
+func (p *Parser) Statements() []*goraptor.Statement { return p.statements }
+
+// Returns the positions of the statements returned by Statements(), if
+// RetainLocators is set.
+func (p *Parser) Locators() []goraptor.Locator { return p.locators }
+
+// Counts of what a Parser read and built, see Parser.Stats().
+type ParseStats struct {
+	Statements int // statements processed
+	Packages   int
+	Files      int
+	Licences   int // listed and extracted licences and licence sets, by node
+	Checksums  int
+	Reviews    int
+	Unresolved int // nodes with statements waiting for their type
+}
+
+// Adds n to the count of the type of the element built by a builder: 1 for a
+// new builder, -1 for the element a builder no longer builds. Licence
+// exceptions, unknown licences and the NONE and NOASSERTION licences are not
+// counted as licences.
+func (s *ParseStats) count(ptr interface{}, n int) {
+	switch ptr.(type) {
+	case *spdx.Package:
+		s.Packages += n
+	case *spdx.File:
+		s.Files += n
+	case *spdx.Checksum:
+		s.Checksums += n
+	case *spdx.Review:
+		s.Reviews += n
+	case *spdx.Licence, *spdx.ExtractedLicence, *spdx.LicenceSet, *spdx.ConjunctiveLicenceSet, *spdx.DisjunctiveLicenceSet:
+		s.Licences += n
+	}
+}
+
+// Returns the counts of the statements processed and of the elements built so
+// far. Unresolved is the number of nodes whose type isn't known yet: at the
+// end of the input, nodes that were never typed.
+func (p *Parser) Stats() ParseStats {
+	stats := p.stats
+	stats.Unresolved = len(p.buffer)
+	return stats
+}
+
+// Returns the warnings collected while parsing. Recoverable problems are only
+// collected if the parser is not in Strict mode. Use of the obsolete terms
+// namespace, of deprecated properties (such as artifactOf) and references to
+// files that are never defined are reported in both modes.
+func (p *Parser) Warnings() []*spdx.ParseError { return p.warnings }
+
+// An io.Reader that records whether the end of r was reached and the last
+// error r returned other than io.EOF. goraptor is given io.EOF instead of the
+// error, and once stop() is called, so that it always finishes its input and
+// closes its channels. src is the input given to the parser, see close().
+type endReader struct {
+	r       io.Reader
+	src     io.Reader
+	eof     bool
+	err     error
+	stopped int32 // set atomically: stop() and Read() run on different goroutines
+}
+
+func (e *endReader) Read(b []byte) (int, error) {
+	if e.err != nil || atomic.LoadInt32(&e.stopped) != 0 {
+		return 0, io.EOF
+	}
+	n, err := e.r.Read(b)
+	if err == io.EOF {
+		e.eof = true
+	} else if err != nil {
+		e.err = err
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Makes the next reads return io.EOF, when the statements still to come are
+// not needed.
+func (e *endReader) stop() { atomic.StoreInt32(&e.stopped, 1) }
+
+// Closes src if it is the read half of an io.Pipe that was not read to the
+// end: the writer would otherwise block forever. It then gets
+// io.ErrClosedPipe.
+func (e *endReader) close() {
+	if pr, ok := e.src.(*io.PipeReader); ok && !e.eof {
+		pr.Close()
+	}
+}
+
+// Returns an error if goraptor stopped before the end of the input, which it
+// does on syntax errors without reporting them, if the input could not be
+// read or if it ends inside the RDF/XML root element. The error is at the
+// line of the last statement read.
+func (p *Parser) checkEnd() error {
+	var meta *spdx.Meta
+	if p.line > 0 {
+		meta = spdx.NewMetaL(p.line)
+	}
+	switch {
+	case p.end == nil:
+		return nil
+	case p.end.err != nil:
+		return spdx.NewParseErrorCode(spdx.ErrUnexpectedEnd, fmt.Sprintf(msgReadError, p.end.err), meta)
+	case !p.end.eof:
+		return spdx.NewParseErrorCode(spdx.ErrUnexpectedEnd, msgInputNotRead, meta)
+	case p.trailer != nil && p.trailer.opened && !p.trailer.closed:
+		return spdx.NewParseErrorCode(spdx.ErrUnexpectedEnd, msgUnclosedRDF, meta)
+	}
+	return nil
+}
+
+// Matches the opening tag of the RDF/XML root element.
+var rdfOpenTag = regexp.MustCompile("<([A-Za-z_][A-Za-z0-9_.-]*:)?RDF[\\s/>]")
+
+// Matches the closing tag of the RDF/XML root element.
+var rdfCloseTag = regexp.MustCompile("</([A-Za-z_][A-Za-z0-9_.-]*:)?RDF\\s*>")
+
+// Number of bytes kept between reads to match a closing tag split across reads.
+const trailingWindow = 64
+
+// An io.Reader that stops at the end of the RDF/XML root element. If anything
+// other than white space follows the closing tag, `trailing` is set, `line` is
+// the line where that content starts and the reader returns io.EOF instead of
+// passing the content to goraptor. `opened` is set if the opening tag of the
+// root element is read.
+type trailingReader struct {
+	r        io.Reader
+	tail     []byte // last bytes read, to match a tag across reads
+	opened   bool   // whether the opening tag was read
+	closed   bool   // whether the closing tag was read
+	trailing bool   // whether content after the closing tag was found
+	line     int    // current line (1-based)
+}
+
+func (t *trailingReader) Read(b []byte) (int, error) {
+	if t.trailing {
+		return 0, io.EOF
+	}
+	n, err := t.r.Read(b)
+	data := b[:n]
+	start := 0
+	if !t.closed {
+		buf := append(t.tail, data...)
+		if !t.opened && rdfOpenTag.Match(buf) {
+			t.opened = true
+		}
+		if loc := rdfCloseTag.FindIndex(buf); loc != nil {
+			t.closed = true
+			start = loc[1] - len(t.tail)
+			if start < 0 {
+				start = 0
+			}
+		} else {
+			if len(buf) > trailingWindow {
+				buf = buf[len(buf)-trailingWindow:]
+			}
+			t.tail = append(t.tail[:0], buf...)
+			t.line += bytes.Count(data, []byte("\n"))
+			return n, err
+		}
+	}
+	t.line += bytes.Count(data[:start], []byte("\n"))
+	for i := start; i < n; i++ {
+		switch data[i] {
+		case '\n':
+			t.line++
+		case ' ', '\t', '\r':
+		default:
+			t.trailing = true
+			t.line++
+			return i, io.EOF
+		}
+	}
+	return n, err
 }
 
 // Free the goraptor parser.
 func (p *Parser) Free() {
-	p.rdfparser.Free()
+	if p.rdfparser != nil {
+		raptorMu.Lock()
+		p.rdfparser.Free()
+		raptorMu.Unlock()
+		p.rdfparser = nil
+	}
 	p.doc = nil
 }
 
+// Returns the error for node, of the type of bldr, being used with the
+// incompatible type t at meta. The error has the position where the type of
+// node was set, if known, as Previous.
+func incompatibleTypes(node goraptor.Term, bldr *builder, t goraptor.Term, meta *spdx.Meta) *spdx.ParseError {
+	if bldr.meta == nil {
+		return spdx.NewParseErrorCode(spdx.ErrIncompatibleTypes, fmt.Sprintf(msgIncompatibleTypes, node, bldr.t, t), meta)
+	}
+	perr := spdx.NewParseErrorCode(spdx.ErrIncompatibleTypes, fmt.Sprintf(msgIncompatibleTypesAt, node, bldr.t, bldr.meta.LineStart, t), meta)
+	perr.Previous = bldr.meta
+	return perr
+}
+
+// Registers a type that is not part of the SPDX specification, such as a
+// vendor extension. Nodes of the type typeURI (a full URI) are built by
+// factory, called with the metadata of the type statement: it returns the
+// element and the updaters of its properties, by property (a full URI or a
+// property of the SPDX terms namespace). Properties without an updater are
+// errors. Registered types are kept by Reset() and cannot replace the
+// built-in ones.
+func (p *Parser) RegisterType(typeURI string, factory func(meta *spdx.Meta) (element interface{}, updaters map[string]Updater)) {
+	if p.types == nil {
+		p.types = make(map[string]func(meta *spdx.Meta) *builder)
+	}
+	p.types[typeURI] = func(meta *spdx.Meta) *builder {
+		el, updaters := factory(meta)
+		bldr := &builder{ptr: el, updaters: make(map[string]updater, len(updaters))}
+		for pred, fn := range updaters {
+			bldr.updaters[shortPrefix(prefix(pred))] = updater(fn)
+		}
+		return bldr
+	}
+}
+
+// Registers an updater for the property predicate on the nodes of the type
+// typeURI (a full URI), such as a vendor property on packages. predicate is a
+// full URI or a property of the SPDX terms namespace. The updater replaces
+// the built-in one, if any, and is kept by Reset().
+func (p *Parser) RegisterProperty(typeURI, predicate string, fn Updater) {
+	if p.properties == nil {
+		p.properties = make(map[string]map[string]updater)
+	}
+	if p.properties[typeURI] == nil {
+		p.properties[typeURI] = make(map[string]updater)
+	}
+	p.properties[typeURI][shortPrefix(prefix(predicate))] = updater(fn)
+}
+
+// Returns the element built for the node nodeID (a URI or a blank node
+// identifier), such as a *spdx.Package. The second value is false if there is
+// no element for the node yet, for instance while its statements wait for its
+// type.
+// While parsing, the element is live: it is the one the parser updates, not a
+// copy, so it can be inspected from an updater registered with
+// RegisterProperty() but only holds the properties read so far. A licence set
+// whose type is not known yet is a *spdx.LicenceSet.
+func (p *Parser) Element(nodeID string) (interface{}, bool) {
+	bldr, ok := p.index[nodeID]
+	if !ok {
+		return nil, false
+	}
+	return bldr.ptr, true
+}
+
 // Set the type of node to t.
 // If the node does not exist, a builder of the required type is created and the buffered
 // statements will be applied in fifo order.
@@ -211,13 +1125,33 @@ func (p *Parser) setType(node, t goraptor.Term, meta *spdx.Meta) (interface{}, e
 	if ok {
 		if !equalTypes(bldr.t, t) && bldr.has("ns:type") {
 			//apply the type change
+			old := bldr.ptr
 			if err := bldr.apply(uri("ns:type"), t, meta); err != nil {
 				return nil, err
 			}
+			p.stats.count(old, -1)
+			p.stats.count(bldr.ptr, 1)
+			p.addProperties(bldr, t)
+			if bldr.meta == nil {
+				bldr.meta = meta
+			}
+			if p.OnType != nil {
+				p.OnType(nodeStr, t)
+			}
 			return bldr.ptr, nil
 		}
 		if !compatibleTypes(bldr.t, t) {
-			return nil, spdx.NewParseError(fmt.Sprintf(msgIncompatibleTypes, node, bldr.t, t), meta)
+			return nil, incompatibleTypes(node, bldr, t, meta)
+		}
+		if meta != nil {
+			setMissingMeta(bldr.ptr, meta)
+			if bldr.meta == nil {
+				// the node was only referenced so far
+				if err := p.defineNodeId(node, bldr.t, meta); err != nil {
+					return nil, err
+				}
+				bldr.meta = meta
+			}
 		}
 		return bldr.ptr, nil
 	}
@@ -225,20 +1159,58 @@ func (p *Parser) setType(node, t goraptor.Term, meta *spdx.Meta) (interface{}, e
 	// new builder by type
 	switch {
 	case t.Equals(typeDocument):
+		if len(p.docs) > 0 {
+			// identifiers are only unique in their document
+			p.ids = make(map[string]idDefinition)
+			// the files of the previous documents are complete
+			if p.OnFileVerify != nil {
+				if err := p.verifyFiles(); err != nil {
+					return nil, err
+				}
+				p.files = nil
+			}
+		}
+		id := nodeId(node)
 		p.doc = &spdx.Document{Meta: meta}
+		if id != "" {
+			p.doc.Id = spdx.Str(id, meta)
+		}
+		p.docs = append(p.docs, p.doc)
 		bldr = p.documentMap(p.doc)
 	case t.Equals(typeCreationInfo):
 		bldr = p.creationInfoMap(&spdx.CreationInfo{Meta: meta})
 	case t.Equals(typePackage):
+		if err := p.defineNodeId(node, t, meta); err != nil {
+			return nil, err
+		}
 		bldr = p.packageMap(&spdx.Package{Meta: meta})
 	case t.Equals(typeChecksum):
 		bldr = p.checksumMap(&spdx.Checksum{Meta: meta})
 	case t.Equals(typeVerificationCode):
 		bldr = p.verificationCodeMap(&spdx.VerificationCode{Meta: meta})
 	case t.Equals(typeFile):
-		bldr = p.fileMap(&spdx.File{Meta: meta})
+		if err := p.defineNodeId(node, t, meta); err != nil {
+			return nil, err
+		}
+		file := &spdx.File{Meta: meta}
+		p.files = append(p.files, file)
+		bldr = p.fileMap(file)
 	case t.Equals(typeReview):
 		bldr = p.reviewMap(&spdx.Review{Meta: meta})
+	case t.Equals(typeAnnotation):
+		bldr = p.annotationMap(&spdx.Annotation{Meta: meta})
+	case t.Equals(typeSnippet):
+		bldr = p.snippetMap(&spdx.Snippet{Meta: meta})
+	case t.Equals(typeStartEndPointer):
+		bldr = p.snippetRangeMap(&spdx.SnippetRange{Meta: meta})
+	case equalTypes(t, typeSinglePointer, typeByteOffsetPointer, typeLineCharPointer):
+		bldr = p.snippetPointerMap(&spdx.SnippetPointer{Meta: meta}, t)
+	case t.Equals(typeExternalRef):
+		bldr = p.externalRefMap(&spdx.ExternalRef{Meta: meta})
+	case t.Equals(typeRelationship):
+		bldr = p.relationshipMap(&spdx.Relationship{Meta: meta})
+	case t.Equals(typeExternalDocumentRef):
+		bldr = p.externalDocumentRefMap(&spdx.ExternalDocumentRef{Meta: meta})
 	case t.Equals(typeArtifactOf):
 		artif := &spdx.ArtifactOf{Meta: meta}
 		if artifUri, ok := node.(*goraptor.Uri); ok {
@@ -253,7 +1225,7 @@ func (p *Parser) setType(node, t goraptor.Term, meta *spdx.Meta) (interface{}, e
 		case *goraptor.Uri: // licence in spdx licence list
 			bldr = p.licenceReferenceBuilder(node, meta)
 		case *goraptor.Blank: // licence reference or abstract set
-			if strings.HasPrefix(strings.ToLower(termStr(t)), "licenseref") {
+			if strings.HasPrefix(strings.ToLower(termStr(t)), "licenseref") || p.bufferedExtractedLicence(nodeStr) {
 				bldr = p.extractedLicensingInfoMap(&spdx.ExtractedLicence{Meta: meta})
 			} else {
 				bldr = p.licenceSetMap(&spdx.LicenceSet{
@@ -273,27 +1245,265 @@ func (p *Parser) setType(node, t goraptor.Term, meta *spdx.Meta) (interface{}, e
 		bldr = p.conjunctiveSetBuilder(meta)
 	case t.Equals(typeDisjunctiveSet):
 		bldr = p.disjuntiveSetBuilder(meta)
+	case t.Equals(typeWithException):
+		with := spdx.NewWithException(meta, nil, nil)
+		bldr = p.withExceptionMap(&with)
+	case t.Equals(typeLicenceException):
+		bldr = p.licenceExceptionMap(&spdx.LicenceException{Meta: meta})
+	case isLicenceType(t):
+		bldr = p.unknownLicenceMap(&spdx.UnknownLicence{Type: spdx.Str(termStr(t), meta), Meta: meta}, t)
+	case p.types[termStr(t)] != nil:
+		bldr = p.types[termStr(t)](meta)
+		bldr.t = t
+	case p.KeepUnknownTypes:
+		el := &spdx.UnknownElement{Node: nodeStr, Type: spdx.Str(termStr(t), meta), Meta: meta}
+		p.unknownElements = append(p.unknownElements, el)
+		bldr = p.unknownElementMap(el, t)
 	default:
-		return nil, spdx.NewParseError(fmt.Sprintf(msgUnknownType, t), meta)
+		if meta != nil {
+			return nil, spdx.NewParseErrorCode(spdx.ErrUnknownType, fmt.Sprintf(msgUnknownTypeLine, t, nodeStr, meta.LineStart), meta)
+		}
+		return nil, spdx.NewParseErrorCode(spdx.ErrUnknownType, fmt.Sprintf(msgUnknownType, t, nodeStr), meta)
+	}
+
+	bldr.meta = meta
+	p.stats.count(bldr.ptr, 1)
+	p.addProperties(bldr, t)
+	setNodeId(bldr.ptr, node)
+	if p.OnType != nil {
+		p.OnType(nodeStr, t)
+	}
+	if err := p.addBuilder(nodeStr, bldr); err != nil {
+		return nil, err
+	}
+	return bldr.ptr, nil
+}
+
+// Adds the updaters registered with RegisterProperty() for the type t to
+// bldr. Called again when the type of a node changes, as its builder may be
+// replaced.
+func (p *Parser) addProperties(bldr *builder, t goraptor.Term) {
+	for pred, fn := range p.properties[termStr(t)] {
+		if bldr.updaters == nil {
+			bldr.updaters = make(map[string]updater)
+		}
+		bldr.updaters[pred] = fn
+	}
+}
+
+// Records the node on licence sets, so that they are refreshed from their
+// builder by finalLicence(), and the blank node identifier on extracted
+// licences, so that they can be matched across documents. Other elements are
+// left as they are.
+func setNodeId(ptr interface{}, node goraptor.Term) {
+	id := termStr(node)
+	switch el := ptr.(type) {
+	case *spdx.LicenceSet:
+		el.NodeId = id
+	case *spdx.ConjunctiveLicenceSet:
+		el.NodeId = id
+	case *spdx.DisjunctiveLicenceSet:
+		el.NodeId = id
+	case *spdx.ExtractedLicence:
+		if _, ok := node.(*goraptor.Blank); ok {
+			el.NodeId = id
+		}
+	}
+}
+
+// Checks whether the statements buffered for node have properties that only
+// extracted licences have, so that a blank node is known to be an extracted
+// licence even if its identifier doesn't start with "LicenseRef".
+func (p *Parser) bufferedExtractedLicence(node string) bool {
+	for _, stm := range p.buffer[node] {
+		switch shortPrefix(stm.Predicate) {
+		case "licenseId", "extractedText":
+			return true
+		}
+	}
+	return false
+}
+
+// Sets the metadata of elements created when they were first referenced,
+// before their type was known. Elements that already have metadata are left
+// as they are.
+func setMissingMeta(ptr interface{}, meta *spdx.Meta) {
+	switch el := ptr.(type) {
+	case *spdx.Document:
+		if el.Meta == nil {
+			el.Meta = meta
+		}
+	case *spdx.Package:
+		if el.Meta == nil {
+			el.Meta = meta
+		}
+	case *spdx.File:
+		if el.Meta == nil {
+			el.Meta = meta
+		}
+	case *spdx.CreationInfo:
+		if el.Meta == nil {
+			el.Meta = meta
+		}
+	case *spdx.Checksum:
+		if el.Meta == nil {
+			el.Meta = meta
+		}
+	case *spdx.ExtractedLicence:
+		if el.Meta == nil {
+			el.Meta = meta
+		}
 	}
+}
 
-	p.index[nodeStr] = bldr
+// Index bldr as the builder of node and apply the statements buffered for node
+// in fifo order.
+func (p *Parser) addBuilder(node string, bldr *builder) error {
+	p.index[node] = bldr
 
 	// run buffer
-	buf := p.buffer[nodeStr]
+	buf := p.buffer[node]
 	for _, stm := range buf {
-		if err := bldr.apply(stm.Predicate, stm.Object, stm.Meta); err != nil {
-			return nil, err
+		if p.headerOnly && !p.inHeader(stm.Statement) {
+			continue
+		}
+		if p.SkipFiles && p.skipFile(stm.Statement) {
+			continue
+		}
+		if err := p.apply(bldr, stm.Predicate, stm.Object, stm.Meta); err != nil {
+			return err
 		}
 	}
-	delete(p.buffer, nodeStr)
+	p.buffered -= len(buf)
+	delete(p.buffer, node)
+	return nil
+}
 
-	return bldr.ptr, nil
+// Where an SPDX identifier is defined: the node that has it (empty for
+// licence IDs) and the position where the node was typed, if known.
+type idDefinition struct {
+	node string
+	meta *spdx.Meta
+}
+
+// Records the SPDX identifier of node, a package or a file, see defineId().
+// Nodes of other types are ignored.
+func (p *Parser) defineNodeId(node, t goraptor.Term, meta *spdx.Meta) error {
+	if !equalTypes(t, typePackage, typeFile) {
+		return nil
+	}
+	return p.defineId(nodeId(node), termStr(node), meta)
+}
+
+// Records id as a used SPDX identifier of node in the current document. If id
+// is already used by another node, or node is empty (licence IDs), a
+// ParseError with both the current meta and the line of the first definition
+// is returned. If node is typed after being referenced, meta is recorded as
+// its position. Empty identifiers are ignored.
+func (p *Parser) defineId(id, node string, meta *spdx.Meta) error {
+	if id == "" {
+		return nil
+	}
+	if p.ids == nil {
+		p.ids = make(map[string]idDefinition)
+	}
+	if first, ok := p.ids[id]; ok {
+		if node != "" && first.node == node {
+			if first.meta == nil {
+				p.ids[id] = idDefinition{node, meta}
+			}
+			return nil
+		}
+		if first.meta != nil {
+			return spdx.NewParseErrorCode(spdx.ErrDuplicateId, fmt.Sprintf(msgDuplicateId, id, first.meta.LineStart), meta)
+		}
+		return spdx.NewParseErrorCode(spdx.ErrDuplicateId, fmt.Sprintf(msgDuplicateIdNoMeta, id), meta)
+	}
+	p.ids[id] = idDefinition{node, meta}
+	return nil
+}
+
+// Returns the SPDX identifier of a URI node, which is the fragment of the URI
+// (the part after "#"). Blank nodes and URIs without a fragment have no
+// identifier and an empty string is returned.
+func nodeId(node goraptor.Term) string {
+	u, ok := node.(*goraptor.Uri)
+	if !ok {
+		return ""
+	}
+	str := string(*u)
+	if i := strings.LastIndex(str, "#"); i >= 0 {
+		return str[i+1:]
+	}
+	return ""
+}
+
+// Apply a property to bldr. If the parser is not in Strict mode, unsupported
+// properties are recorded as warnings instead of returning an error.
+func (p *Parser) apply(bldr *builder, pred, obj goraptor.Term, meta *spdx.Meta) error {
+	if p.CaseInsensitiveProperties {
+		if property := shortPrefix(pred); !bldr.has(property) {
+			if supported := bldr.fold(property); supported != "" {
+				pred = prefix(supported)
+			}
+		}
+	}
+	if !p.Strict {
+		if property := shortPrefix(pred); !bldr.has(property) && bldr.other == nil {
+			p.unknown++
+			p.warnings = append(p.warnings, spdx.NewParseErrorCode(spdx.ErrPropertyNotSupported, fmt.Sprintf(msgPropertyNotSupported, property, bldr.t), meta))
+			return nil
+		}
+	}
+	p.known++
+	if err := bldr.apply(pred, obj, meta); err != nil {
+		return err
+	}
+	if property := shortPrefix(pred); bldr.deprecated[property] {
+		p.warnings = append(p.warnings, spdx.NewParseErrorCode(spdx.ErrDeprecated, fmt.Sprintf(msgDeprecatedProperty, property, bldr.t), meta))
+	}
+	return nil
+}
+
+// Returns an error if MaxUnknownRatio is set and the ratio of unsupported to
+// supported properties is greater than it.
+func (p *Parser) checkUnknownRatio() error {
+	if p.MaxUnknownRatio <= 0 || p.unknown == 0 {
+		return nil
+	}
+	if p.known == 0 || float64(p.unknown)/float64(p.known) > p.MaxUnknownRatio {
+		return spdx.NewParseErrorCode(spdx.ErrTooManyUnknown, fmt.Sprintf(msgTooManyUnknown, p.unknown, p.known), nil)
+	}
+	return nil
 }
 
 // Process a SPDX Truple.
 func (p *Parser) processTruple(stm *goraptor.Statement, meta *spdx.Meta) error {
+	p.stats.Statements++
+	if p.Trace != nil {
+		p.trace(stm, meta)
+	}
+	stm = p.aliasStatement(stm)
+	if pfx := unboundPrefix(stm.Predicate); pfx != "" {
+		return p.unboundPrefix(pfx, stm.Predicate, meta)
+	}
+	if pfx := unboundPrefix(stm.Object); pfx != "" && stm.Predicate.Equals(uri_nstype) {
+		return p.unboundPrefix(pfx, stm.Object, meta)
+	}
+	stm = p.normalizeStatement(stm, meta)
+	if p.headerOnly && !p.inHeader(stm) {
+		return nil
+	}
+	if p.SkipFiles && p.skipFile(stm) {
+		return nil
+	}
 	node := termStr(stm.Subject)
+	if _, ok := p.seq[node]; !ok {
+		if p.seq == nil {
+			p.seq = make(map[string]int)
+		}
+		p.seq[node] = len(p.seq)
+	}
 	if stm.Predicate.Equals(uri_nstype) {
 		_, err := p.setType(stm.Subject, stm.Object, meta)
 		return err
@@ -302,18 +1512,302 @@ func (p *Parser) processTruple(stm *goraptor.Statement, meta *spdx.Meta) error {
 	// apply function if it's a builder
 	bldr, ok := p.index[node]
 	if ok {
-		return bldr.apply(stm.Predicate, stm.Object, meta)
+		return p.apply(bldr, stm.Predicate, stm.Object, meta)
 	}
 
 	// buffer statement
 	if _, ok := p.buffer[node]; !ok {
 		p.buffer[node] = make([]bufferEntry, 0)
 	}
+	if p.MaxBuffered > 0 && p.buffered >= p.MaxBuffered {
+		return spdx.NewParseErrorCode(spdx.ErrLimitExceeded, fmt.Sprintf(msgTooManyBuffered, p.MaxBuffered), meta)
+	}
 	p.buffer[node] = append(p.buffer[node], bufferEntry{stm, meta})
+	p.buffered++
 
 	return nil
 }
 
+// Reports the use of the unbound prefix pfx in term. It is an error in Strict
+// mode. Otherwise a warning is recorded for the first use of every prefix and
+// the statement is ignored.
+func (p *Parser) unboundPrefix(pfx string, term goraptor.Term, meta *spdx.Meta) error {
+	perr := spdx.NewParseErrorCode(spdx.ErrUnboundPrefix, fmt.Sprintf(msgUnboundPrefix, strings.TrimSuffix(pfx, ":"), term), meta)
+	if p.Strict {
+		return perr
+	}
+	if !p.unbound[pfx] {
+		if p.unbound == nil {
+			p.unbound = make(map[string]bool)
+		}
+		p.unbound[pfx] = true
+		p.warnings = append(p.warnings, perr)
+	}
+	return nil
+}
+
+// Writes stm to Trace in N-Triples, followed by its line as a comment.
+func (p *Parser) trace(stm *goraptor.Statement, meta *spdx.Meta) {
+	if meta != nil {
+		fmt.Fprintf(p.Trace, "%s %s %s . # line %d\n", stm.Subject.N3(), stm.Predicate.N3(), stm.Object.N3(), meta.LineStart)
+	} else {
+		fmt.Fprintf(p.Trace, "%s %s %s .\n", stm.Subject.N3(), stm.Predicate.N3(), stm.Object.N3())
+	}
+}
+
+// Sorts the packages and files of doc, and the files of its packages, in the
+// order in which they first appeared as subjects in the input. The sort is
+// stable and elements that were not read by this parser are moved to the end.
+func (p *Parser) SortBySource(doc *spdx.Document) {
+	order := make(map[interface{}]int, len(p.seq))
+	for node, bldr := range p.index {
+		if seq, ok := p.seq[node]; ok {
+			order[bldr.ptr] = seq
+		}
+	}
+	pos := func(el interface{}) int {
+		if seq, ok := order[el]; ok {
+			return seq
+		}
+		return len(p.seq)
+	}
+	sortFiles := func(files []*spdx.File) {
+		sort.SliceStable(files, func(i, j int) bool { return pos(files[i]) < pos(files[j]) })
+	}
+	sort.SliceStable(doc.Packages, func(i, j int) bool { return pos(doc.Packages[i]) < pos(doc.Packages[j]) })
+	sortFiles(doc.Files)
+	for _, pkg := range doc.Packages {
+		if pkg != nil {
+			sortFiles(pkg.Files)
+		}
+	}
+}
+
+// Identifies a file by its name and checksum. Files without a checksum have no
+// identity (empty string) and are never merged.
+func DefaultFileIdentity(f *spdx.File) string {
+	if f.Checksum == nil || f.Checksum.Value.Val == "" {
+		return ""
+	}
+	return f.Name.Val + "\x00" + f.Checksum.Algo.Val + "\x00" + f.Checksum.Value.Val
+}
+
+// Replaces the files with the same identity by the first of them, in the
+// document files, package files and file dependencies.
+func (p *Parser) dedupFiles() {
+	if p.doc == nil {
+		return
+	}
+	identity := p.FileIdentity
+	if identity == nil {
+		identity = DefaultFileIdentity
+	}
+	seen := make(map[string]*spdx.File)
+	var unique []*spdx.File
+	dedup := func(files []*spdx.File) {
+		for i, f := range files {
+			if f == nil {
+				continue
+			}
+			id := identity(f)
+			if id == "" {
+				continue
+			}
+			if first, ok := seen[id]; ok {
+				files[i] = first
+			} else {
+				seen[id] = f
+				unique = append(unique, f)
+			}
+		}
+	}
+	dedup(p.doc.Files)
+	for _, pkg := range p.doc.Packages {
+		dedup(pkg.Files)
+	}
+	// unique grows while dependencies are deduplicated
+	for i := 0; i < len(unique); i++ {
+		dedup(unique[i].Dependency)
+	}
+}
+
+// Types that can be inferred from the properties of a node.
+var inferableTypes = []goraptor.Term{
+	typeCreationInfo,
+	typePackage,
+	typeFile,
+	typeChecksum,
+	typeVerificationCode,
+	typeReview,
+	typeAnnotation,
+	typeArtifactOf,
+	typeExtractedLicence,
+	typeLicenceException,
+}
+
+// Returns an empty builder of type t, used only to check which properties t
+// supports.
+func (p *Parser) emptyBuilder(t goraptor.Term) *builder {
+	switch {
+	case t.Equals(typeCreationInfo):
+		return p.creationInfoMap(new(spdx.CreationInfo))
+	case t.Equals(typePackage):
+		return p.packageMap(new(spdx.Package))
+	case t.Equals(typeFile):
+		return p.fileMap(new(spdx.File))
+	case t.Equals(typeChecksum):
+		return p.checksumMap(new(spdx.Checksum))
+	case t.Equals(typeVerificationCode):
+		return p.verificationCodeMap(new(spdx.VerificationCode))
+	case t.Equals(typeReview):
+		return p.reviewMap(new(spdx.Review))
+	case t.Equals(typeAnnotation):
+		return p.annotationMap(new(spdx.Annotation))
+	case t.Equals(typeArtifactOf):
+		return p.artifactOfMap(new(spdx.ArtifactOf))
+	case t.Equals(typeExtractedLicence):
+		return p.extractedLicensingInfoMap(new(spdx.ExtractedLicence))
+	case t.Equals(typeLicenceException):
+		return p.licenceExceptionMap(new(spdx.LicenceException))
+	}
+	return nil
+}
+
+// Sets the type of the nodes that still have buffered statements because their
+// type was never found. A node gets a type only if exactly one of the
+// inferable types supports all its properties; other nodes are left in the
+// buffer. Nodes are processed in lexical order so the result doesn't depend on
+// map iteration.
+func (p *Parser) inferTypes() error {
+	builders := make([]*builder, len(inferableTypes))
+	for i, t := range inferableTypes {
+		builders[i] = p.emptyBuilder(t)
+	}
+
+	nodes := make([]string, 0, len(p.buffer))
+	for node := range p.buffer {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	for _, node := range nodes {
+		buf, ok := p.buffer[node]
+		if !ok || len(buf) == 0 {
+			continue
+		}
+		var found goraptor.Term
+		for i, bldr := range builders {
+			if !supportsAll(bldr, buf) {
+				continue
+			}
+			if found != nil {
+				found = nil
+				break
+			}
+			found = inferableTypes[i]
+		}
+		if found == nil {
+			continue
+		}
+		if _, err := p.setType(buf[0].Subject, found, buf[0].Meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks whether bldr has updaters for all the predicates in buf.
+func supportsAll(bldr *builder, buf []bufferEntry) bool {
+	for _, stm := range buf {
+		if !bldr.has(shortPrefix(stm.Predicate)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Namespaces that AddNamespace() accepts, by prefix.
+var namespacePrefixes = map[string]string{
+	"spdx": baseUri,
+	"rdf":  rdfPrefixes["ns:"],
+	"ns":   rdfPrefixes["ns:"],
+	"rdfs": rdfPrefixes["rdfs:"],
+	"doap": rdfPrefixes["doap:"],
+	"ptr":  rdfPrefixes["ptr:"],
+}
+
+// Reads the terms starting with uri as terms of the namespace of prefix, one of
+// "spdx", "rdf", "rdfs", "doap" and "ptr" (a trailing ":" is allowed). For
+// example, after AddNamespace("spdx", "http://example.org/spdx#"), the
+// predicate http://example.org/spdx#name is read as spdx:name. uri can also be
+// an unbound prefix such as "s:". Other prefixes are ignored. Namespaces are
+// kept by Reset().
+func (p *Parser) AddNamespace(prefix, uri string) {
+	ns, ok := namespacePrefixes[strings.TrimSuffix(prefix, ":")]
+	if !ok || uri == "" {
+		return
+	}
+	if p.namespaces == nil {
+		p.namespaces = make(map[string]string)
+	}
+	p.namespaces[uri] = ns
+}
+
+// Returns stm with the predicate and the object moved from the namespaces
+// added with AddNamespace() to their canonical namespaces.
+func (p *Parser) aliasStatement(stm *goraptor.Statement) *goraptor.Statement {
+	if len(p.namespaces) == 0 {
+		return stm
+	}
+	pred, predAlias := p.aliasTerm(stm.Predicate)
+	obj, objAlias := p.aliasTerm(stm.Object)
+	if !predAlias && !objAlias {
+		return stm
+	}
+	alias := *stm
+	alias.Predicate, alias.Object = pred, obj
+	return &alias
+}
+
+// Returns t moved to its canonical namespace if it is a URI in a namespace
+// added with AddNamespace(), the longest one if there are several. The second
+// value is true if t was moved.
+func (p *Parser) aliasTerm(t goraptor.Term) (goraptor.Term, bool) {
+	u, ok := t.(*goraptor.Uri)
+	if !ok {
+		return t, false
+	}
+	str := string(*u)
+	var match string
+	for alias := range p.namespaces {
+		if len(alias) > len(match) && strings.HasPrefix(str, alias) {
+			match = alias
+		}
+	}
+	if match == "" {
+		return t, false
+	}
+	return uri(p.namespaces[match] + str[len(match):]), true
+}
+
+// Returns stm with the predicate and the object moved from the obsolete terms
+// namespace to the current one. A warning is recorded the first time the
+// obsolete namespace is found.
+func (p *Parser) normalizeStatement(stm *goraptor.Statement, meta *spdx.Meta) *goraptor.Statement {
+	pred, predOld := normalizeTerm(stm.Predicate)
+	obj, objOld := normalizeTerm(stm.Object)
+	if !predOld && !objOld {
+		return stm
+	}
+	if !p.obsolete {
+		p.obsolete = true
+		p.warnings = append(p.warnings, spdx.NewParseErrorCode(spdx.ErrDeprecated, msgObsoleteNamespace, meta))
+	}
+	norm := *stm
+	norm.Predicate, norm.Object = pred, obj
+	return &norm
+}
+
 // Checks if found is any of the need types. Note: a type term of type
 // goraptor.Uri is not the same type as one of type goraptor.Blank; same
 // applies for other combinations.
@@ -328,15 +1822,37 @@ func equalTypes(found goraptor.Term, need ...goraptor.Term) bool {
 
 // Checks if found is the same as need.
 //
-// If need is any of typeLicence, typeDisjunctiveSet, typeConjunctiveSet
-// and typeExtractedLicence and found is AnyLicence, it  is permitted and
-// the function returns true.
+// If need is any of typeLicence, typeDisjunctiveSet, typeConjunctiveSet,
+// typeExtractedLicence and typeWithException and found is AnyLicence, it is
+// permitted and the function returns true.
 func compatibleTypes(found, need goraptor.Term) bool {
 	if equalTypes(found, need) {
 		return true
 	}
+	if equalTypes(need, typeSinglePointer) {
+		return equalTypes(found, typeByteOffsetPointer, typeLineCharPointer)
+	}
 	if equalTypes(need, typeAnyLicence) {
-		return equalTypes(found, typeExtractedLicence, typeConjunctiveSet, typeDisjunctiveSet, typeLicence)
+		return equalTypes(found, typeExtractedLicence, typeConjunctiveSet, typeDisjunctiveSet, typeLicence, typeWithException, typeAbstractLicenceSet) ||
+			isLicenceType(found)
+	}
+	return false
+}
+
+// Checks whether t is a licence type that is not supported: a type in the
+// SPDX terms namespace that names a licence (contains "License") or a licence
+// operator (ends with "Operator").
+func isLicenceType(t goraptor.Term) bool {
+	u, ok := t.(*goraptor.Uri)
+	if !ok || !strings.HasPrefix(string(*u), baseUri) {
+		return false
+	}
+	name := string(*u)[len(baseUri):]
+	switch {
+	case equalTypes(t, typeExtractedLicence, typeConjunctiveSet, typeDisjunctiveSet, typeLicence, typeWithException, typeAnyLicence, typeLicenceException):
+		return false
+	case strings.Contains(name, "License"), strings.HasSuffix(name, "Operator"):
+		return true
 	}
 	return false
 }
@@ -355,7 +1871,7 @@ func (p *Parser) reqType(node, t goraptor.Term) (interface{}, error) {
 	bldr, ok := p.index[termStr(node)]
 	if ok {
 		if !compatibleTypes(bldr.t, t) {
-			return nil, fmt.Errorf(msgIncompatibleTypes, node, bldr.t, t)
+			return nil, incompatibleTypes(node, bldr, t, nil)
 		}
 		return bldr.ptr, nil
 	}
@@ -397,119 +1913,763 @@ func (p *Parser) reqVerificationCode(node goraptor.Term) (*spdx.VerificationCode
 	}
 	return obj.(*spdx.VerificationCode), err
 }
-func (p *Parser) reqChecksum(node goraptor.Term) (*spdx.Checksum, error) {
-	obj, err := p.reqType(node, typeChecksum)
-	if err != nil {
-		return nil, err
+func (p *Parser) reqChecksum(node goraptor.Term) (*spdx.Checksum, error) {
+	obj, err := p.reqType(node, typeChecksum)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.Checksum), err
+}
+func (p *Parser) reqExternalDocumentRef(node goraptor.Term) (*spdx.ExternalDocumentRef, error) {
+	obj, err := p.reqType(node, typeExternalDocumentRef)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.ExternalDocumentRef), err
+}
+func (p *Parser) reqRelationship(node goraptor.Term) (*spdx.Relationship, error) {
+	obj, err := p.reqType(node, typeRelationship)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.Relationship), err
+}
+func (p *Parser) reqExternalRef(node goraptor.Term) (*spdx.ExternalRef, error) {
+	obj, err := p.reqType(node, typeExternalRef)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.ExternalRef), err
+}
+func (p *Parser) reqAnnotation(node goraptor.Term) (*spdx.Annotation, error) {
+	obj, err := p.reqType(node, typeAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.Annotation), err
+}
+func (p *Parser) reqSnippet(node goraptor.Term) (*spdx.Snippet, error) {
+	obj, err := p.reqType(node, typeSnippet)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.Snippet), err
+}
+func (p *Parser) reqSnippetRange(node goraptor.Term) (*spdx.SnippetRange, error) {
+	obj, err := p.reqType(node, typeStartEndPointer)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.SnippetRange), err
+}
+func (p *Parser) reqSnippetPointer(node goraptor.Term) (*spdx.SnippetPointer, error) {
+	obj, err := p.reqType(node, typeSinglePointer)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.SnippetPointer), err
+}
+func (p *Parser) reqReview(node goraptor.Term) (*spdx.Review, error) {
+	obj, err := p.reqType(node, typeReview)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.Review), err
+}
+func (p *Parser) reqExtractedLicence(node goraptor.Term) (*spdx.ExtractedLicence, error) {
+	obj, err := p.reqType(node, typeExtractedLicence)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.ExtractedLicence), err
+}
+func (p *Parser) reqAnyLicence(node goraptor.Term) (spdx.AnyLicence, error) {
+	obj, err := p.reqType(node, typeAnyLicence)
+	if err != nil {
+		return nil, err
+	}
+	switch lic := obj.(type) {
+	case *spdx.AnyLicence:
+		return *lic, nil
+	case *spdx.ConjunctiveLicenceSet:
+		return *lic, nil
+	case *spdx.DisjunctiveLicenceSet:
+		return *lic, nil
+	case *[]spdx.AnyLicence:
+		return nil, nil
+	case *spdx.Licence:
+		// interned, see internLicence()
+		return lic, nil
+	case *spdx.ExtractedLicence:
+		return lic, nil
+	case *spdx.WithException:
+		return *lic, nil
+	case *spdx.UnknownLicence:
+		return lic, nil
+	case *spdx.NoAssertionLicence:
+		return *lic, nil
+	case *spdx.NoneLicence:
+		return *lic, nil
+	case *spdx.LicenceSet:
+		// the type of the set is not known yet: it is replaced by the set at
+		// the end of the input
+		placeholder := &spdx.UnknownLicence{Type: spdx.Str(termStr(typeAbstractLicenceSet), lic.Meta), Meta: lic.Meta}
+		if p.pendingSets == nil {
+			p.pendingSets = make(map[*spdx.UnknownLicence]string)
+		}
+		p.pendingSets[placeholder] = termStr(node)
+		return placeholder, nil
+	default:
+		return nil, fmt.Errorf("Unexpected error, an element of type AnyLicence cannot be casted to any licence type. %s || %#v", node, obj)
+	}
+}
+func (p *Parser) reqLicenceException(node goraptor.Term) (*spdx.LicenceException, error) {
+	obj, err := p.reqType(node, typeLicenceException)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.LicenceException), err
+}
+func (p *Parser) reqArtifactOf(node goraptor.Term) (*spdx.ArtifactOf, error) {
+	obj, err := p.reqType(node, typeArtifactOf)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*spdx.ArtifactOf), err
+}
+
+// Returns a *builder for doc.
+func (p *Parser) documentMap(doc *spdx.Document) *builder {
+	bldr := &builder{t: typeDocument, ptr: doc}
+
+	// builder of the creation information inlined on the document, if any
+	var inlinedInfo *builder
+
+	// Creation information both inlined and in a CreationInfo node is an
+	// error, or a warning if not in Strict mode: the CreationInfo node is kept
+	// and the inlined properties are dropped.
+	bothInfo := func(meta *spdx.Meta) error {
+		perr := spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgInlinedCreationInfo, meta)
+		if p.Strict {
+			return perr
+		}
+		p.warnings = append(p.warnings, perr)
+		return nil
+	}
+
+	bldr.updaters = map[string]updater{
+		"specVersion":       upd(&doc.SpecVersion),
+		"documentNamespace": upd(&doc.Namespace),
+		"dataLicense":       updCutPrefix(licenceUri, &doc.DataLicence),
+		"rdfs:comment":      upd(&doc.Comment),
+		"creationInfo": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if inlinedInfo != nil {
+				if err := bothInfo(meta); err != nil {
+					return err
+				}
+				inlinedInfo = nil
+			}
+			cri, err := p.reqCreationInfo(obj)
+			doc.CreationInfo = cri
+			return err
+		},
+		"describesPackage": func(obj goraptor.Term, meta *spdx.Meta) error {
+			pkg, err := p.reqPackage(obj)
+			if err != nil {
+				return err
+			}
+			// the same package node can be described more than once
+			for _, described := range doc.Packages {
+				if described == pkg {
+					return nil
+				}
+			}
+			doc.Packages = append(doc.Packages, pkg)
+			return nil
+		},
+		"referencesFile": func(obj goraptor.Term, meta *spdx.Meta) error {
+			file, err := p.refFile(obj, "referencesFile", meta)
+			if err != nil {
+				return err
+			}
+			doc.Files = append(doc.Files, file)
+			return nil
+		},
+		"reviewed": func(obj goraptor.Term, meta *spdx.Meta) error {
+			rev, err := p.reqReview(obj)
+			if err != nil {
+				return err
+			}
+			doc.Reviews = append(doc.Reviews, rev)
+			return nil
+		},
+		"hasExtractedLicensingInfo": func(obj goraptor.Term, meta *spdx.Meta) error {
+			lic, err := p.reqExtractedLicence(obj)
+			if err != nil {
+				return err
+			}
+			doc.ExtractedLicences = append(doc.ExtractedLicences, lic)
+			return nil
+		},
+		"relationship": func(obj goraptor.Term, meta *spdx.Meta) error {
+			rel, err := p.reqRelationship(obj)
+			if err != nil {
+				return err
+			}
+			doc.Relationships = append(doc.Relationships, rel)
+			return nil
+		},
+		"annotation": func(obj goraptor.Term, meta *spdx.Meta) error {
+			ann, err := p.reqAnnotation(obj)
+			if err != nil {
+				return err
+			}
+			doc.Annotations = append(doc.Annotations, ann)
+			return nil
+		},
+		"snippet": func(obj goraptor.Term, meta *spdx.Meta) error {
+			snip, err := p.reqSnippet(obj)
+			if err != nil {
+				return err
+			}
+			doc.Snippets = append(doc.Snippets, snip)
+			return nil
+		},
+		"externalDocumentRef": func(obj goraptor.Term, meta *spdx.Meta) error {
+			ref, err := p.reqExternalDocumentRef(obj)
+			if err != nil {
+				return err
+			}
+			doc.ExternalDocumentRefs = append(doc.ExternalDocumentRefs, ref)
+			return nil
+		},
+	}
+
+	// Creation information inlined on the document, accepted only when not in
+	// Strict mode. A CreationInfo is created for the inlined properties, which
+	// share its builder so that they are defined only once.
+	inlined := func(property string) updater {
+		return func(obj goraptor.Term, meta *spdx.Meta) error {
+			if p.Strict {
+				return spdx.NewParseErrorCode(spdx.ErrPropertyNotSupported, fmt.Sprintf(msgPropertyNotSupported, property, bldr.t), meta)
+			}
+			if inlinedInfo == nil {
+				if doc.CreationInfo != nil {
+					return bothInfo(meta)
+				}
+				doc.CreationInfo = &spdx.CreationInfo{Meta: meta}
+				inlinedInfo = p.creationInfoMap(doc.CreationInfo)
+			}
+			return inlinedInfo.apply(prefix(property), obj, meta)
+		}
+	}
+	for _, property := range []string{"creator", "created", "licenseListVersion"} {
+		bldr.updaters[property] = inlined(property)
+	}
+
+	return bldr
+}
+
+// Returns a builder for cri.
+func (p *Parser) creationInfoMap(cri *spdx.CreationInfo) *builder {
+	bldr := &builder{t: typeCreationInfo, ptr: cri}
+	listVersion := upd(&cri.LicenceListVersion)
+	bldr.updaters = map[string]updater{
+		"creator":      updListCreator(&cri.Creator),
+		"rdfs:comment": upd(&cri.Comment),
+		"created":      updDate(&cri.Created),
+		"licenseListVersion": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if v := termStr(obj); v != "" && !listVersionFormat.MatchString(v) {
+				return spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgListVersion, v), meta)
+			}
+			return listVersion(obj, meta)
+		},
+	}
+	return bldr
+}
+
+// Format of the dates, as a time.Parse layout.
+const dateFormat = "2006-01-02T15:04:05Z"
+
+// Format of the licence list version: major.minor.
+var listVersionFormat = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// Returns a builder for rev.
+func (p *Parser) externalDocumentRefMap(ref *spdx.ExternalDocumentRef) *builder {
+	bldr := &builder{t: typeExternalDocumentRef, ptr: ref}
+	bldr.updaters = map[string]updater{
+		"externalDocumentId": upd(&ref.Id),
+		"spdxDocument":       upd(&ref.Document),
+		"checksum": func(obj goraptor.Term, meta *spdx.Meta) error {
+			cksum, err := p.reqChecksum(obj)
+			ref.Checksum = cksum
+			return err
+		},
+	}
+	return bldr
+}
+
+func (p *Parser) relationshipMap(rel *spdx.Relationship) *builder {
+	bldr := &builder{t: typeRelationship, ptr: rel}
+	bldr.updaters = map[string]updater{
+		"relationshipType": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if rel.Type.Val != "" {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+			}
+			typ := relationshipType(termStr(obj))
+			if !spdx.IsRelationshipType(typ) {
+				perr := spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgRelationshipType, termStr(obj)), meta)
+				if p.Strict {
+					return perr
+				}
+				p.warnings = append(p.warnings, perr)
+			}
+			rel.Type = spdx.Str(typ, meta)
+			return nil
+		},
+		"relatedSpdxElement": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if err := upd(&rel.Related)(obj, meta); err != nil {
+				return err
+			}
+			p.related = append(p.related, relatedElement{rel, termStr(obj)})
+			return nil
+		},
+		"rdfs:comment": upd(&rel.Comment),
+	}
+	return bldr
+}
+
+// Calls OnFileVerify for every file found and not verified yet.
+func (p *Parser) verifyFiles() error {
+	for _, file := range p.files {
+		ok, err := p.OnFileVerify(file)
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+		perr := spdx.NewParseErrorCode(spdx.ErrVerification, fmt.Sprintf(msgFileVerification, file.Name.Val), file.Meta)
+		if p.Strict {
+			return perr
+		}
+		p.warnings = append(p.warnings, perr)
+	}
+	return nil
+}
+
+// Gives the identifiers LicenseRef-1, LicenseRef-2... to the extracted
+// licences that have no licenseId, in the order in which their nodes first
+// appeared as subjects in the input, so that the document can be written
+// again. Identifiers already used by other licences are skipped.
+func (p *Parser) assignLicenseRefs() {
+	used := make(map[string]bool)
+	var nodes []string
+	for node, bldr := range p.index {
+		if lic, ok := bldr.ptr.(*spdx.ExtractedLicence); ok {
+			if lic.Id.Val == "" {
+				nodes = append(nodes, node)
+			} else {
+				used[lic.Id.Val] = true
+			}
+		}
+	}
+	pos := func(node string) int {
+		if seq, ok := p.seq[node]; ok {
+			return seq
+		}
+		return len(p.seq)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if pi, pj := pos(nodes[i]), pos(nodes[j]); pi != pj {
+			return pi < pj
+		}
+		return nodes[i] < nodes[j]
+	})
+	n := 0
+	for _, node := range nodes {
+		id := ""
+		for id == "" || used[id] {
+			n++
+			id = fmt.Sprintf("LicenseRef-%d", n)
+		}
+		lic := p.index[node].ptr.(*spdx.ExtractedLicence)
+		lic.Id = spdx.Str(id, lic.Meta)
+	}
+}
+
+// Reports the licence sets whose type was never set to a conjunctive or
+// disjunctive set, in the order of their nodes. They are errors in Strict mode
+// and warnings otherwise.
+func (p *Parser) checkAbstractSets() error {
+	var nodes []string
+	for node, bldr := range p.index {
+		if bldr.t.Equals(typeAbstractLicenceSet) {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		set := p.index[node].ptr.(*spdx.LicenceSet)
+		perr := spdx.NewParseErrorCode(spdx.ErrUnknownType, fmt.Sprintf(msgAbstractSet, node), set.Meta)
+		if p.Strict {
+			return perr
+		}
+		p.warnings = append(p.warnings, perr)
+	}
+	return nil
+}
+
+// Replaces the licence sets of the packages and files by their final
+// contents. Sets are stored by value, so a set referenced before all its
+// members were read is an incomplete copy, and a set referenced before its
+// type was known is a placeholder (see reqAnyLicence()). Sets whose type is
+// never found are left as spdx.UnknownLicence with the type
+// AbstractLicenceSet.
+func (p *Parser) resolveLicenceSets() {
+	for _, bldr := range p.index {
+		switch el := bldr.ptr.(type) {
+		case *spdx.Package:
+			el.LicenceConcluded = p.finalLicence(el.LicenceConcluded, nil)
+			el.LicenceDeclared = p.finalLicence(el.LicenceDeclared, nil)
+			for i, lic := range el.LicenceInfoFromFiles {
+				el.LicenceInfoFromFiles[i] = p.finalLicence(lic, nil)
+			}
+		case *spdx.File:
+			el.LicenceConcluded = p.finalLicence(el.LicenceConcluded, nil)
+			for i, lic := range el.LicenceInfoInFile {
+				el.LicenceInfoInFile[i] = p.finalLicence(lic, nil)
+			}
+		case *spdx.Snippet:
+			el.LicenceConcluded = p.finalLicence(el.LicenceConcluded, nil)
+			for i, lic := range el.LicenceInfoInSnippet {
+				el.LicenceInfoInSnippet[i] = p.finalLicence(lic, nil)
+			}
+		}
+	}
+}
+
+// Returns lic, and its members, with the sets replaced by the contents of
+// their builders. visiting holds the nodes of the enclosing sets, so that a
+// set that contains itself is not expanded forever.
+func (p *Parser) finalLicence(lic spdx.AnyLicence, visiting map[string]bool) spdx.AnyLicence {
+	var node string
+	switch l := lic.(type) {
+	case spdx.ConjunctiveLicenceSet:
+		node = l.NodeId
+	case spdx.DisjunctiveLicenceSet:
+		node = l.NodeId
+	case *spdx.UnknownLicence:
+		node = p.pendingSets[l]
+	}
+	if visiting[node] {
+		return lic
+	}
+	if bldr, ok := p.index[node]; ok {
+		if visiting == nil {
+			visiting = make(map[string]bool)
+		}
+		visiting[node] = true
+		defer delete(visiting, node)
+		switch set := bldr.ptr.(type) {
+		case *spdx.ConjunctiveLicenceSet:
+			lic = *set
+		case *spdx.DisjunctiveLicenceSet:
+			lic = *set
+		case *spdx.WithException:
+			lic = *set
+		case *spdx.UnknownLicence:
+			lic = set
+		case *spdx.LicenceSet:
+			if u, ok := lic.(*spdx.UnknownLicence); ok {
+				u.Members = set.Members
+			}
+		}
+	}
+
+	members := func(list []spdx.AnyLicence) {
+		for i, m := range list {
+			list[i] = p.finalLicence(m, visiting)
+		}
+	}
+	switch l := lic.(type) {
+	case spdx.ConjunctiveLicenceSet:
+		members(l.Members)
+	case spdx.DisjunctiveLicenceSet:
+		members(l.Members)
+	case *spdx.UnknownLicence:
+		members(l.Members)
+	case spdx.WithException:
+		l.Licence = p.finalLicence(l.Licence, visiting)
+		return l
+	}
+	return lic
+}
+
+// A relationship and the node of its related element.
+type relatedElement struct {
+	rel  *spdx.Relationship
+	node string
+}
+
+// Sets the element of the relationships whose related node is defined in the
+// document. The node can be any element, including the document itself.
+func (p *Parser) resolveRelated() {
+	for _, r := range p.related {
+		if bldr, ok := p.index[r.node]; ok {
+			r.rel.Element = bldr.ptr
+		}
+	}
+}
+
+// A reference to a file with the property and the position of the statement
+// that references it.
+type fileRef struct {
+	node     string
+	property string
+	meta     *spdx.Meta
+}
+
+// Like reqFile but records the reference, see checkFileRefs().
+func (p *Parser) refFile(node goraptor.Term, property string, meta *spdx.Meta) (*spdx.File, error) {
+	file, err := p.reqFile(node)
+	if err == nil {
+		p.fileRefs = append(p.fileRefs, fileRef{termStr(node), property, meta})
 	}
-	return obj.(*spdx.Checksum), err
+	return file, err
 }
-func (p *Parser) reqReview(node goraptor.Term) (*spdx.Review, error) {
-	obj, err := p.reqType(node, typeReview)
-	if err != nil {
-		return nil, err
+
+// Reports the files referenced with hasFile, referencesFile or fileDependency
+// that are never typed: their node is most likely missing from the input. They
+// are reported as warnings, at the position of the reference, in both modes.
+func (p *Parser) checkFileRefs() {
+	for _, ref := range p.fileRefs {
+		if bldr, ok := p.index[ref.node]; ok && bldr.meta == nil {
+			p.warnings = append(p.warnings, spdx.NewParseErrorCode(spdx.ErrUndefinedElement, fmt.Sprintf(msgUndefinedFile, ref.node, ref.property), ref.meta))
+		}
 	}
-	return obj.(*spdx.Review), err
 }
-func (p *Parser) reqExtractedLicence(node goraptor.Term) (*spdx.ExtractedLicence, error) {
-	obj, err := p.reqType(node, typeExtractedLicence)
-	if err != nil {
-		return nil, err
+
+// A file excluded from a verification code by reference and the index of its
+// name in the excluded files.
+type excludedFile struct {
+	vc   *spdx.VerificationCode
+	i    int
+	file *spdx.File
+}
+
+// Sets the names of the files excluded from verification codes by reference,
+// which are only known at the end of the input.
+func (p *Parser) resolveExcludedFiles() {
+	for _, ex := range p.excluded {
+		ex.vc.ExcludedFiles[ex.i].Val = ex.file.Name.Val
 	}
-	return obj.(*spdx.ExtractedLicence), err
 }
-func (p *Parser) reqAnyLicence(node goraptor.Term) (spdx.AnyLicence, error) {
-	obj, err := p.reqType(node, typeAnyLicence)
-	if err != nil {
-		return nil, err
+
+// Converts a relationship type URI such as
+// "http://spdx.org/rdf/terms#relationshipType_dependsOn" to the value used in
+// the tag format ("DEPENDS_ON").
+func relationshipType(str string) string {
+	return upperWords(strings.TrimPrefix(str, baseUri+"relationshipType_"), '_')
+}
+
+// Converts a file type URI such as "http://spdx.org/rdf/terms#fileType_source"
+// or "http://spdx.org/rdf/terms#SOURCE" to the value used in the tag format
+// ("SOURCE").
+func fileType(str string) string {
+	return strings.ToUpper(strings.TrimPrefix(strings.TrimPrefix(str, baseUri), "fileType_"))
+}
+
+// Converts a reference category URI such as
+// "http://spdx.org/rdf/terms#referenceCategory_packageManager" to the value
+// used in the tag format ("PACKAGE-MANAGER").
+func referenceCategory(str string) string {
+	return upperWords(strings.TrimPrefix(str, baseUri+"referenceCategory_"), '-')
+}
+
+// Converts a primary package purpose URI such as
+// "http://spdx.org/rdf/terms#purpose_operatingSystem" to the value used in the
+// tag format ("OPERATING-SYSTEM").
+func primaryPurpose(str string) string {
+	return upperWords(strings.TrimPrefix(str, baseUri+"purpose_"), '-')
+}
+
+// Converts a camel case string to upper case, with sep between words.
+func upperWords(str string, sep byte) string {
+	var buf bytes.Buffer
+	for i, r := range str {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			buf.WriteByte(sep)
+		}
+		buf.WriteRune(r)
 	}
-	switch lic := obj.(type) {
-	case *spdx.AnyLicence:
-		return *lic, nil
-	case *spdx.ConjunctiveLicenceSet:
-		return *lic, nil
-	case *spdx.DisjunctiveLicenceSet:
-		return *lic, nil
-	case *[]spdx.AnyLicence:
-		return nil, nil
-	case *spdx.Licence:
-		return *lic, nil
-	case *spdx.ExtractedLicence:
-		return lic, nil
-	default:
-		return nil, fmt.Errorf("Unexpected error, an element of type AnyLicence cannot be casted to any licence type. %s || %#v", node, obj)
+	return strings.ToUpper(buf.String())
+}
+
+func (p *Parser) externalRefMap(ref *spdx.ExternalRef) *builder {
+	bldr := &builder{t: typeExternalRef, ptr: ref}
+	bldr.updaters = map[string]updater{
+		"referenceCategory": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if ref.Category.Val != "" {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+			}
+			category := referenceCategory(termStr(obj))
+			if !spdx.IsReferenceCategory(category) {
+				perr := spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgReferenceCategory, termStr(obj)), meta)
+				if p.Strict {
+					return perr
+				}
+				p.warnings = append(p.warnings, perr)
+			}
+			ref.Category = spdx.Str(category, meta)
+			return nil
+		},
+		"referenceType":    updCutPrefix(referenceTypeUri, &ref.Type),
+		"referenceLocator": upd(&ref.Locator),
+		"rdfs:comment":     upd(&ref.Comment),
 	}
+	return bldr
 }
-func (p *Parser) reqArtifactOf(node goraptor.Term) (*spdx.ArtifactOf, error) {
-	obj, err := p.reqType(node, typeArtifactOf)
-	if err != nil {
-		return nil, err
+
+func (p *Parser) annotationMap(ann *spdx.Annotation) *builder {
+	bldr := &builder{t: typeAnnotation, ptr: ann}
+	bldr.updaters = map[string]updater{
+		"annotator":      updCreator(&ann.Annotator),
+		"annotationDate": updDate(&ann.Date),
+		"annotationType": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if ann.Type.Val != "" {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+			}
+			typ := annotationType(termStr(obj))
+			if !spdx.IsAnnotationType(typ) {
+				perr := spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgAnnotationType, termStr(obj)), meta)
+				if p.Strict {
+					return perr
+				}
+				p.warnings = append(p.warnings, perr)
+			}
+			ann.Type = spdx.Str(typ, meta)
+			return nil
+		},
+		"rdfs:comment": upd(&ann.Comment),
 	}
-	return obj.(*spdx.ArtifactOf), err
+	return bldr
 }
 
-// Returns a *builder for doc.
-func (p *Parser) documentMap(doc *spdx.Document) *builder {
-	bldr := &builder{t: typeDocument, ptr: doc}
+// Converts an annotation type URI such as
+// "http://spdx.org/rdf/terms#annotationType_review" to the value used in the
+// tag format ("REVIEW").
+func annotationType(str string) string {
+	return strings.ToUpper(strings.TrimPrefix(str, baseUri+"annotationType_"))
+}
+
+// Returns a builder for snip.
+func (p *Parser) snippetMap(snip *spdx.Snippet) *builder {
+	bldr := &builder{t: typeSnippet, ptr: snip}
 	bldr.updaters = map[string]updater{
-		"specVersion":  upd(&doc.SpecVersion),
-		"dataLicense":  updCutPrefix(licenceUri, &doc.DataLicence),
-		"rdfs:comment": upd(&doc.Comment),
-		"creationInfo": func(obj goraptor.Term, meta *spdx.Meta) error {
-			cri, err := p.reqCreationInfo(obj)
-			doc.CreationInfo = cri
+		"name": upd(&snip.Name),
+		"snippetFromFile": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if snip.File != nil {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+			}
+			file, err := p.refFile(obj, "snippetFromFile", meta)
+			snip.File = file
 			return err
 		},
-		"describesPackage": func(obj goraptor.Term, meta *spdx.Meta) error {
-			pkg, err := p.reqPackage(obj)
+		"range": func(obj goraptor.Term, meta *spdx.Meta) error {
+			r, err := p.reqSnippetRange(obj)
 			if err != nil {
 				return err
 			}
-			doc.Packages = append(doc.Packages, pkg)
+			snip.Ranges = append(snip.Ranges, r)
 			return nil
 		},
-		"referencesFile": func(obj goraptor.Term, meta *spdx.Meta) error {
-			file, err := p.reqFile(obj)
+		"licenseConcluded": func(obj goraptor.Term, meta *spdx.Meta) error {
+			lic, err := p.reqAnyLicence(obj)
+			snip.LicenceConcluded = lic
+			return err
+		},
+		"licenseInfoInSnippet": func(obj goraptor.Term, meta *spdx.Meta) error {
+			lic, err := p.reqAnyLicence(obj)
 			if err != nil {
 				return err
 			}
-			doc.Files = append(doc.Files, file)
+			snip.LicenceInfoInSnippet = append(snip.LicenceInfoInSnippet, lic)
 			return nil
 		},
-		"reviewed": func(obj goraptor.Term, meta *spdx.Meta) error {
-			rev, err := p.reqReview(obj)
-			if err != nil {
-				return err
+		"licenseComments": upd(&snip.LicenceComments),
+		"copyrightText":   upd(&snip.CopyrightText),
+		"rdfs:comment":    upd(&snip.Comment),
+	}
+	return bldr
+}
+
+// Returns a builder for r, a range of a snippet.
+func (p *Parser) snippetRangeMap(r *spdx.SnippetRange) *builder {
+	bldr := &builder{t: typeStartEndPointer, ptr: r}
+	bldr.updaters = map[string]updater{
+		"ptr:startPointer": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if r.Start != nil {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
 			}
-			doc.Reviews = append(doc.Reviews, rev)
-			return nil
+			ptr, err := p.reqSnippetPointer(obj)
+			r.Start = ptr
+			return err
 		},
-		"hasExtractedLicensingInfo": func(obj goraptor.Term, meta *spdx.Meta) error {
-			lic, err := p.reqExtractedLicence(obj)
-			if err != nil {
-				return err
+		"ptr:endPointer": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if r.End != nil {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
 			}
-			doc.ExtractedLicences = append(doc.ExtractedLicences, lic)
-			return nil
+			ptr, err := p.reqSnippetPointer(obj)
+			r.End = ptr
+			return err
 		},
 	}
-
 	return bldr
 }
 
-// Returns a builder for cri.
-func (p *Parser) creationInfoMap(cri *spdx.CreationInfo) *builder {
-	bldr := &builder{t: typeCreationInfo, ptr: cri}
+// Returns a builder for ptr, of type t. A pointer created as a
+// ptr:SinglePointer, because a range refers to it before its type is known,
+// takes the type ptr:ByteOffsetPointer or ptr:LineCharPointer when it is found.
+func (p *Parser) snippetPointerMap(ptr *spdx.SnippetPointer, t goraptor.Term) *builder {
+	bldr := &builder{t: t, ptr: ptr}
 	bldr.updaters = map[string]updater{
-		"creator":            updListCreator(&cri.Creator),
-		"rdfs:comment":       upd(&cri.Comment),
-		"created":            updDate(&cri.Created),
-		"licenseListVersion": upd(&cri.LicenceListVersion),
+		"ptr:offset":     upd(&ptr.Offset),
+		"ptr:lineNumber": upd(&ptr.LineNumber),
+		"ptr:reference": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if ptr.Reference != nil {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+			}
+			file, err := p.reqFile(obj)
+			ptr.Reference = file
+			return err
+		},
+		"ns:type": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if !equalTypes(bldr.t, typeSinglePointer) || !equalTypes(obj, typeByteOffsetPointer, typeLineCharPointer) {
+				return spdx.NewParseErrorCode(spdx.ErrIncompatibleTypes, fmt.Sprintf(msgIncompatibleTypes, "Pointer", bldr.t, obj), meta)
+			}
+			bldr.t = obj
+			return nil
+		},
+	}
+	return bldr
+}
+
+// Returns a builder for el, an element of the unknown type t, that keeps all
+// the properties of the element.
+func (p *Parser) unknownElementMap(el *spdx.UnknownElement, t goraptor.Term) *builder {
+	bldr := &builder{t: t, ptr: el}
+	bldr.other = func(pred, obj goraptor.Term, meta *spdx.Meta) error {
+		if el.Properties == nil {
+			el.Properties = make(map[string][]spdx.ValueStr)
+		}
+		property := termStr(pred)
+		el.Properties[property] = append(el.Properties[property], spdx.Str(termStr(obj), meta))
+		return nil
 	}
 	return bldr
 }
 
-// Returns a builder for rev.
 func (p *Parser) reviewMap(rev *spdx.Review) *builder {
 	bldr := &builder{t: typeReview, ptr: rev}
 	bldr.updaters = map[string]updater{
@@ -535,13 +2695,29 @@ func (p *Parser) packageMap(pkg *spdx.Package) *builder {
 			pkg.VerificationCode = vc
 			return err
 		},
+		"filesAnalyzed": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if pkg.FilesAnalyzed != nil {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+			}
+			var analyzed bool
+			switch termStr(obj) {
+			case "true":
+				analyzed = true
+			case "false":
+			default:
+				return spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgFilesAnalyzed, termStr(obj)), meta)
+			}
+			pkg.FilesAnalyzed = &analyzed
+			return nil
+		},
 		"checksum": func(obj goraptor.Term, meta *spdx.Meta) error {
 			cksum, err := p.reqChecksum(obj)
 			pkg.Checksum = cksum
 			return err
 		},
 		"doap:homepage": upd(&pkg.HomePage),
-		"sourceInfo":    upd(&pkg.SourceInfo),
+		"rdfs:seeAlso":  updList(&pkg.SeeAlso),
+		"sourceInfo":    updText(&pkg.SourceInfo),
 		"licenseConcluded": func(obj goraptor.Term, meta *spdx.Meta) error {
 			lic, err := p.reqAnyLicence(obj)
 			pkg.LicenceConcluded = lic
@@ -561,17 +2737,61 @@ func (p *Parser) packageMap(pkg *spdx.Package) *builder {
 			return err
 		},
 		"licenseComments": upd(&pkg.LicenceComments),
-		"copyrightText":   upd(&pkg.CopyrightText),
+		"copyrightText":   updCopyright(&pkg.CopyrightText, &pkg.CopyrightSentinel),
+		"attributionText": updList(&pkg.AttributionText),
 		"summary":         upd(&pkg.Summary),
 		"description":     upd(&pkg.Description),
+		"rdfs:comment":    upd(&pkg.Comment),
+		"primaryPackagePurpose": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if pkg.PrimaryPurpose.Val != "" {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+			}
+			purpose := primaryPurpose(termStr(obj))
+			if !spdx.IsPrimaryPurpose(purpose) {
+				perr := spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgPrimaryPurpose, termStr(obj)), meta)
+				if p.Strict {
+					return perr
+				}
+				p.warnings = append(p.warnings, perr)
+			}
+			pkg.PrimaryPurpose = spdx.Str(purpose, meta)
+			return nil
+		},
+		"releaseDate":    updDate(&pkg.ReleaseDate),
+		"builtDate":      updDate(&pkg.BuiltDate),
+		"validUntilDate": updDate(&pkg.ValidUntilDate),
 		"hasFile": func(obj goraptor.Term, meta *spdx.Meta) error {
-			file, err := p.reqFile(obj)
+			file, err := p.refFile(obj, "hasFile", meta)
 			if err != nil {
 				return err
 			}
 			pkg.Files = append(pkg.Files, file)
 			return nil
 		},
+		"relationship": func(obj goraptor.Term, meta *spdx.Meta) error {
+			rel, err := p.reqRelationship(obj)
+			if err != nil {
+				return err
+			}
+			pkg.Relationships = append(pkg.Relationships, rel)
+			return nil
+		},
+		"annotation": func(obj goraptor.Term, meta *spdx.Meta) error {
+			ann, err := p.reqAnnotation(obj)
+			if err != nil {
+				return err
+			}
+			pkg.Annotations = append(pkg.Annotations, ann)
+			return nil
+		},
+		"externalRef": func(obj goraptor.Term, meta *spdx.Meta) error {
+			ref, err := p.reqExternalRef(obj)
+			if err != nil {
+				return err
+			}
+			pkg.ExternalRefs = append(pkg.ExternalRefs, ref)
+			return nil
+		},
 	}
 	return bldr
 }
@@ -580,19 +2800,36 @@ func (p *Parser) packageMap(pkg *spdx.Package) *builder {
 func (p *Parser) checksumMap(cksum *spdx.Checksum) *builder {
 	bldr := &builder{t: typeChecksum, ptr: cksum}
 	algoSet := false
+	value := upd(&cksum.Value)
+	// the value is trimmed, lowercased and checked against the algorithm
+	// once both are known
+	check := func(meta *spdx.Meta) error {
+		if cksum.Value.Val == "" {
+			return nil
+		}
+		if cksum.Canonicalize() != nil {
+			return spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgChecksumValue, cksum.Value.Val, cksum.Algo.Val), meta)
+		}
+		return nil
+	}
 	bldr.updaters = map[string]updater{
 		"algorithm": func(obj goraptor.Term, meta *spdx.Meta) error {
 			if algoSet {
-				return spdx.NewParseError(msgAlreadyDefined, meta)
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
 			}
 			str := termStr(obj)
 			str = strings.Replace(str, "http://spdx.org/rdf/terms#checksumAlgorithm_", "", 1)
 			str = strings.ToUpper(str)
 			cksum.Algo.Val, cksum.Algo.Meta = str, meta
 			algoSet = true
-			return nil
+			return check(meta)
+		},
+		"checksumValue": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if err := value(obj, meta); err != nil {
+				return err
+			}
+			return check(meta)
 		},
-		"checksumValue": upd(&cksum.Value),
 	}
 	return bldr
 }
@@ -600,9 +2837,75 @@ func (p *Parser) checksumMap(cksum *spdx.Checksum) *builder {
 // Returns a builder for vc.
 func (p *Parser) verificationCodeMap(vc *spdx.VerificationCode) *builder {
 	bldr := &builder{t: typeVerificationCode, ptr: vc}
+	value := upd(&vc.Value)
+	bldr.updaters = map[string]updater{
+		"packageVerificationCodeValue": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if _, ok := obj.(*goraptor.Literal); ok {
+				return value(obj, meta)
+			}
+			// the value is in a nested, checksum-like, node
+			node := termStr(obj)
+			nested := p.verificationCodeValueMap(vc, value)
+			other, ok := p.index[node]
+			if !ok {
+				return p.addBuilder(node, nested)
+			}
+			cksum, typed := other.ptr.(*spdx.Checksum)
+			if !typed {
+				return spdx.NewParseErrorCode(spdx.ErrIncompatibleTypes, fmt.Sprintf(msgIncompatibleTypes, node, other.t, typeNestedValue), meta)
+			}
+			// the node is typed spdx:Checksum: what was read of the checksum
+			// goes to vc and the next properties are read as a nested value
+			if cksum.Algo.Val != "" && cksum.Algo.Val != "SHA1" {
+				return spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgVerifCodeAlgorithm, cksum.Algo.Val), cksum.Algo.Meta)
+			}
+			if cksum.Value.Val != "" {
+				if err := value(literal(cksum.Value.Val), cksum.Value.Meta); err != nil {
+					return err
+				}
+			}
+			nested.meta = other.meta
+			p.index[node] = nested
+			return nil
+		},
+		"packageVerificationCodeExcludedFile": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if _, ok := obj.(*goraptor.Literal); ok {
+				return updList(&vc.ExcludedFiles)(obj, meta)
+			}
+			// reference to a file, whose name may not be known yet
+			file, err := p.reqFile(obj)
+			if err != nil {
+				return err
+			}
+			p.excluded = append(p.excluded, excludedFile{vc, len(vc.ExcludedFiles), file})
+			vc.ExcludedFiles = append(vc.ExcludedFiles, spdx.Str(file.Name.Val, meta))
+			return nil
+		},
+	}
+	return bldr
+}
+
+// Returns a builder for a node that holds the value of vc, similar to a
+// checksum. The value is set using `value`. The node can be typed
+// spdx:Checksum.
+func (p *Parser) verificationCodeValueMap(vc *spdx.VerificationCode, value updater) *builder {
+	bldr := &builder{t: typeNestedValue, ptr: vc}
 	bldr.updaters = map[string]updater{
-		"packageVerificationCodeValue":        upd(&vc.Value),
-		"packageVerificationCodeExcludedFile": updList(&vc.ExcludedFiles),
+		"ns:type": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if !equalTypes(obj, typeChecksum) {
+				return spdx.NewParseErrorCode(spdx.ErrIncompatibleTypes, fmt.Sprintf(msgIncompatibleTypes, "Verification code value", bldr.t, obj), meta)
+			}
+			return nil
+		},
+		"checksumValue": value,
+		"ns:value":      value,
+		"algorithm": func(obj goraptor.Term, meta *spdx.Meta) error {
+			algo := strings.TrimPrefix(termStr(obj), "http://spdx.org/rdf/terms#checksumAlgorithm_")
+			if strings.ToUpper(algo) != "SHA1" {
+				return spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgVerifCodeAlgorithm, algo), meta)
+			}
+			return nil
+		},
 	}
 	return bldr
 }
@@ -610,17 +2913,45 @@ func (p *Parser) verificationCodeMap(vc *spdx.VerificationCode) *builder {
 // Returns a builder for file.
 func (p *Parser) fileMap(file *spdx.File) *builder {
 	bldr := &builder{t: typeFile, ptr: file}
+	setName := upd(&file.Name)
 	bldr.updaters = map[string]updater{
-		"fileName":     upd(&file.Name),
+		"fileName": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if err := setName(obj, meta); err != nil {
+				return err
+			}
+			if p.NormalizeFileNames {
+				if name := spdx.NormalizeFileName(file.Name.Val); name != file.Name.Val {
+					file.OriginalName = file.Name
+					file.Name = spdx.Str(name, meta)
+				}
+			}
+			return nil
+		},
 		"rdfs:comment": upd(&file.Comment),
-		"fileType":     updCutPrefix("http://spdx.org/rdf/terms#", &file.Type),
+		"fileType": func(obj goraptor.Term, meta *spdx.Meta) error {
+			typ := fileType(termStr(obj))
+			if !spdx.IsFileType(typ) {
+				perr := spdx.NewParseErrorCode(spdx.ErrInvalidValue, fmt.Sprintf(msgFileType, termStr(obj)), meta)
+				if p.Strict {
+					return perr
+				}
+				p.warnings = append(p.warnings, perr)
+			}
+			if file.Type.Val == "" {
+				file.Type = spdx.Str(typ, meta)
+			} else {
+				file.ExtraTypes = append(file.ExtraTypes, spdx.Str(typ, meta))
+			}
+			return nil
+		},
 		"checksum": func(obj goraptor.Term, meta *spdx.Meta) error {
 			cksum, err := p.reqChecksum(obj)
 			file.Checksum = cksum
 			return err
 		},
-		"copyrightText": upd(&file.CopyrightText),
-		"noticeText":    upd(&file.Notice),
+		"copyrightText":   updCopyright(&file.CopyrightText, &file.CopyrightSentinel),
+		"noticeText":      upd(&file.Notice),
+		"attributionText": updList(&file.AttributionText),
 		"licenseConcluded": func(obj goraptor.Term, meta *spdx.Meta) error {
 			lic, err := p.reqAnyLicence(obj)
 			file.LicenceConcluded = lic
@@ -636,8 +2967,25 @@ func (p *Parser) fileMap(file *spdx.File) *builder {
 		},
 		"licenseComments": upd(&file.LicenceComments),
 		"fileContributor": updList(&file.Contributor),
+		"rdfs:seeAlso":    updList(&file.SeeAlso),
+		"relationship": func(obj goraptor.Term, meta *spdx.Meta) error {
+			rel, err := p.reqRelationship(obj)
+			if err != nil {
+				return err
+			}
+			file.Relationships = append(file.Relationships, rel)
+			return nil
+		},
+		"annotation": func(obj goraptor.Term, meta *spdx.Meta) error {
+			ann, err := p.reqAnnotation(obj)
+			if err != nil {
+				return err
+			}
+			file.Annotations = append(file.Annotations, ann)
+			return nil
+		},
 		"fileDependency": func(obj goraptor.Term, meta *spdx.Meta) error {
-			f, err := p.reqFile(obj)
+			f, err := p.refFile(obj, "fileDependency", meta)
 			if err != nil {
 				return err
 			}
@@ -653,6 +3001,8 @@ func (p *Parser) fileMap(file *spdx.File) *builder {
 			return nil
 		},
 	}
+	// replaced by GENERATED_FROM relationships in SPDX 2.1, see File.Origins()
+	bldr.deprecate("artifactOf")
 	return bldr
 }
 
@@ -662,16 +3012,26 @@ func (p *Parser) artifactOfMap(artif *spdx.ArtifactOf) *builder {
 	bldr.updaters = map[string]updater{
 		"doap:name":     upd(&artif.Name),
 		"doap:homepage": upd(&artif.HomePage),
+		"doap:revision": upd(&artif.Revision),
+		"doap:wiki":     upd(&artif.Wiki),
 	}
+	bldr.deprecate("doap:name", "doap:homepage", "doap:revision", "doap:wiki")
 	return bldr
 }
 
 // Returns a builder for lic.
 func (p *Parser) extractedLicensingInfoMap(lic *spdx.ExtractedLicence) *builder {
 	bldr := &builder{t: typeExtractedLicence, ptr: lic}
+	set := upd(&lic.Id)
 	bldr.updaters = map[string]updater{
-		"licenseId":     upd(&lic.Id),
+		"licenseId": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if err := set(obj, meta); err != nil {
+				return err
+			}
+			return p.defineId(termStr(obj), "", meta)
+		},
 		"name":          updList(&lic.Name),
+		"rdfs:label":    updList(&lic.Name),
 		"extractedText": upd(&lic.Text),
 		"rdfs:comment":  upd(&lic.Comment),
 		"rdfs:seeAlso":  updList(&lic.CrossReference),
@@ -682,18 +3042,33 @@ func (p *Parser) extractedLicensingInfoMap(lic *spdx.ExtractedLicence) *builder
 // Returns a builder for set.
 func (p *Parser) licenceSetMap(set abstractLicenceSet) *builder {
 	bldr := &builder{t: typeAbstractLicenceSet, ptr: set}
+	members := 0
+	seen := make(map[string]bool)
 	bldr.updaters = map[string]updater{
 		"member": func(obj goraptor.Term, meta *spdx.Meta) error {
 			lic, err := p.reqAnyLicence(obj)
 			if err != nil {
 				return err
 			}
+			if p.DedupSetMembers {
+				key := termStr(obj)
+				if l, ok := lic.(*spdx.Licence); ok {
+					key = licenceUri + l.LicenceId()
+				}
+				if seen[key] {
+					return nil
+				}
+				seen[key] = true
+			}
+			if members++; p.MaxSetMembers > 0 && members > p.MaxSetMembers {
+				return spdx.NewParseErrorCode(spdx.ErrLimitExceeded, fmt.Sprintf(msgTooManyMembers, p.MaxSetMembers), meta)
+			}
 			set.Add(lic)
 			return nil
 		},
 		"ns:type": func(obj goraptor.Term, meta *spdx.Meta) error {
 			if !equalTypes(bldr.t, typeAbstractLicenceSet) {
-				return spdx.NewParseError(msgAlreadyDefined, meta)
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
 			}
 			tmpSet := set.(*spdx.LicenceSet)
 			goodMeta := tmpSet.Meta
@@ -703,22 +3078,86 @@ func (p *Parser) licenceSetMap(set abstractLicenceSet) *builder {
 			if equalTypes(obj, typeConjunctiveSet) {
 				bldr.t = typeConjunctiveSet
 				conj := spdx.NewConjunctiveSet(goodMeta, tmpSet.Members...)
+				conj.NodeId = tmpSet.NodeId
 				set = &conj
 				bldr.ptr = &conj
 			} else if equalTypes(obj, typeDisjunctiveSet) {
 				bldr.t = typeDisjunctiveSet
 				disj := spdx.NewDisjunctiveSet(goodMeta, tmpSet.Members...)
+				disj.NodeId = tmpSet.NodeId
 				set = &disj
 				bldr.ptr = &disj
+			} else if equalTypes(obj, typeWithException) && len(tmpSet.Members) <= 1 {
+				with := spdx.NewWithException(goodMeta, nil, nil)
+				if len(tmpSet.Members) == 1 {
+					with.Licence = tmpSet.Members[0]
+				}
+				*bldr = *p.withExceptionMap(&with)
+				bldr.meta = goodMeta
+			} else if isLicenceType(obj) {
+				unknown := &spdx.UnknownLicence{Type: spdx.Str(termStr(obj), meta), Members: tmpSet.Members, Meta: goodMeta}
+				*bldr = *p.unknownLicenceMap(unknown, obj)
+				bldr.meta = goodMeta
 			} else {
-				return spdx.NewParseError(fmt.Sprintf(msgIncompatibleTypes, "Licence Set", bldr.t, obj), meta)
+				return spdx.NewParseErrorCode(spdx.ErrIncompatibleTypes, fmt.Sprintf(msgIncompatibleTypes, "Licence Set", bldr.t, obj), meta)
+			}
+			return nil
+		},
+	}
+	return bldr
+}
+
+// Returns a builder for with.
+func (p *Parser) withExceptionMap(with *spdx.WithException) *builder {
+	bldr := &builder{t: typeWithException, ptr: with}
+	bldr.updaters = map[string]updater{
+		"member": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if with.Licence != nil {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+			}
+			lic, err := p.reqAnyLicence(obj)
+			with.Licence = lic
+			return err
+		},
+		"licenseException": func(obj goraptor.Term, meta *spdx.Meta) error {
+			if with.Exception != nil {
+				return spdx.NewParseErrorCode(spdx.ErrAlreadyDefined, msgAlreadyDefined, meta)
+			}
+			exception, err := p.reqLicenceException(obj)
+			with.Exception = exception
+			return err
+		},
+	}
+	return bldr
+}
+
+// Builder for licences of a type that is not supported. The members of the
+// licence are kept; other properties are not supported.
+func (p *Parser) unknownLicenceMap(lic *spdx.UnknownLicence, t goraptor.Term) *builder {
+	bldr := &builder{t: t, ptr: lic}
+	bldr.updaters = map[string]updater{
+		"member": func(obj goraptor.Term, meta *spdx.Meta) error {
+			member, err := p.reqAnyLicence(obj)
+			if err != nil {
+				return err
 			}
+			lic.Members = append(lic.Members, member)
 			return nil
 		},
 	}
 	return bldr
 }
 
+// Returns a builder for exception.
+func (p *Parser) licenceExceptionMap(exception *spdx.LicenceException) *builder {
+	bldr := &builder{t: typeLicenceException, ptr: exception}
+	bldr.updaters = map[string]updater{
+		"licenseExceptionId": upd(&exception.Id),
+		"name":               upd(&exception.Name),
+	}
+	return bldr
+}
+
 // Returns a builder for a new ConjunctiveLicenceSet.
 func (p *Parser) conjunctiveSetBuilder(meta *spdx.Meta) *builder {
 	set := spdx.NewConjunctiveSet(meta, make([]spdx.AnyLicence, 0)...)
@@ -742,8 +3181,53 @@ func licenceReferenceTerm(node goraptor.Term, meta *spdx.Meta) *spdx.Licence {
 	return &lic
 }
 
-// Creates a builder for a new Licence, using `node` as the value.
+// Creates a builder for a new Licence, using `node` as the value. If the node
+// is a licence with an exception (e.g. "GPL-2.0 WITH Classpath-exception-2.0"),
+// the builder is for the corresponding WithException. The spdx:noassertion and
+// spdx:none resources are spdx.NoAssertionLicence and spdx.NoneLicence.
 func (p *Parser) licenceReferenceBuilder(node goraptor.Term, meta *spdx.Meta) *builder {
-	lic := licenceReferenceTerm(node, meta)
+	switch {
+	case node.Equals(prefix("noassertion")), termStr(node) == licenceUri+spdx.NOASSERTION:
+		return &builder{t: typeLicence, ptr: &spdx.NoAssertionLicence{Meta: meta}}
+	case node.Equals(prefix("none")), termStr(node) == licenceUri+spdx.NONE:
+		return &builder{t: typeLicence, ptr: &spdx.NoneLicence{Meta: meta}}
+	}
+	lic := p.internLicence(licenceReferenceTerm(node, meta))
+	if id := licenceExceptionId(lic.V()); id != "" {
+		if with, err := ParseLicenceExpression(id, meta); err == nil {
+			if w, ok := with.(spdx.WithException); ok {
+				return &builder{t: typeWithException, ptr: &w}
+			}
+		}
+	}
 	return &builder{t: typeLicence, ptr: lic}
 }
+
+// Returns the licence with the same ID as lic parsed before, or lic if it is
+// the first licence with this ID. All the references to a licence ID share the
+// same *spdx.Licence, with the metadata of the first one: the elements of the
+// document hold this pointer.
+func (p *Parser) internLicence(lic *spdx.Licence) *spdx.Licence {
+	if p.licences == nil {
+		p.licences = make(map[string]*spdx.Licence)
+	}
+	if first, ok := p.licences[lic.V()]; ok {
+		return first
+	}
+	p.licences[lic.V()] = lic
+	return lic
+}
+
+// If the licence ID `id` has an exception, returns the ID with unescaped
+// spaces. Returns an empty string otherwise.
+func licenceExceptionId(id string) string {
+	if unescaped, err := url.QueryUnescape(id); err == nil {
+		id = unescaped
+	}
+	for _, word := range strings.Fields(id) {
+		if strings.EqualFold(word, "with") {
+			return id
+		}
+	}
+	return ""
+}
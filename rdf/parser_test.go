@@ -3,9 +3,16 @@ package rdf
 import "testing"
 
 import (
+	"bytes"
 	"errors"
 	"github.com/vladvelici/goraptor"
 	"github.com/vladvelici/spdx-go/spdx"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing/iotest"
+	"time"
 )
 
 // Test goraptor term to string
@@ -93,6 +100,17 @@ func TestUpdCreator(t *testing.T) {
 	if err == nil {
 		t.Errorf("Incorrect error %+v", err)
 	}
+
+	for _, date := range []string{"Person: Mr. Tester", "2010-02-03", "2010-02-03T00:00:00+01:00", "2010-13-03T00:00:00Z", ""} {
+		a := spdx.NewValueDate("", nil)
+		err := updDate(&a)(literal(date), meta)
+		if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrInvalidValue || perr.LineStart != 3 {
+			t.Errorf("Wrong error for date %#v: %#v", date, err)
+		}
+		if a.V() != "" {
+			t.Errorf("Invalid date %#v set.", date)
+		}
+	}
 }
 
 func TestUpdListCreator(t *testing.T) {
@@ -127,6 +145,34 @@ func TestUpdDate(t *testing.T) {
 	}
 }
 
+func TestUpdCopyright(t *testing.T) {
+	tests := []struct {
+		term     goraptor.Term
+		val      string
+		sentinel spdx.Sentinel
+	}{
+		{literal("Copyright 2014 Example"), "Copyright 2014 Example", spdx.NotSentinel},
+		{literal("NOASSERTION"), "NOASSERTION", spdx.SentinelNoAssertion},
+		{literal("NONE"), "NONE", spdx.SentinelNone},
+		{prefix("noassertion"), "NOASSERTION", spdx.SentinelNoAssertion},
+		{prefix("none"), "NONE", spdx.SentinelNone},
+	}
+	for _, test := range tests {
+		var text spdx.ValueStr
+		var sentinel spdx.Sentinel
+		f := updCopyright(&text, &sentinel)
+		if err := f(test.term, spdx.NewMetaL(1)); err != nil {
+			t.Errorf("Unexpected error %s", err)
+		}
+		if text.Val != test.val || text.Meta == nil || sentinel != test.sentinel {
+			t.Errorf("Found %#v (sentinel %d) for %s.", text, sentinel, test.term)
+		}
+		if err := f(test.term, nil); err == nil {
+			t.Error("No error for a second copyright text.")
+		}
+	}
+}
+
 func TestBuilder(t *testing.T) {
 	a := "hello"
 	var meta *spdx.Meta
@@ -494,7 +540,7 @@ func TestCreationInfoMap(t *testing.T) {
 		"creator":            "Person: Testaculous",
 		"rdfs:comment":       "test comment",
 		"created":            "2014-01-01T09:40:57Z",
-		"licenseListVersion": "11",
+		"licenseListVersion": "1.20",
 	}
 
 	for k, v := range statements {
@@ -696,7 +742,7 @@ func TestChecksumMap(t *testing.T) {
 
 	statements := map[string]string{
 		"algorithm":     "http://spdx.org/rdf/terms#checksumAlgorithm_sha1",
-		"checksumValue": "somedummyvalue",
+		"checksumValue": "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12",
 	}
 
 	for k, v := range statements {
@@ -876,3 +922,2814 @@ func TestReqTypeParse(t *testing.T) {
 		t.Errorf("Found %T: %#v", lic, err)
 	}
 }
+
+func TestDuplicateIds(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+
+	first := spdx.NewMetaL(10)
+	if _, err := parser.setType(uri("http://example.org/a#SPDXRef-1"), typePackage, first); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err := parser.setType(uri("http://example.org/b#SPDXRef-1"), typeFile, spdx.NewMetaL(20))
+	if err == nil {
+		t.Fatal("No error for duplicate SPDX identifier.")
+	}
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.LineStart != 20 || !strings.Contains(perr.Error(), "10") {
+		t.Errorf("Error does not contain both positions: %#v", err)
+	}
+
+	// blank nodes have no identifiers
+	if _, err := parser.setType(blank("SPDXRef-1"), typeFile, nil); err != nil {
+		t.Errorf("Unexpected error for blank node: %s", err)
+	}
+
+	// the position of a node referenced before being typed is where it is typed
+	pkg := uri("http://example.org/a#SPDXRef-2")
+	if _, err := parser.reqType(pkg, typePackage); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if _, err := parser.setType(pkg, typePackage, spdx.NewMetaL(12)); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	_, err = parser.setType(uri("http://example.org/b#SPDXRef-2"), typePackage, spdx.NewMetaL(22))
+	if err == nil || !strings.Contains(err.Error(), "12") {
+		t.Errorf("Error does not contain the position of the typed node: %#v", err)
+	}
+
+	// identifiers are only unique in their document
+	if _, err := parser.setType(uri("http://example.org/a#SPDXRef-DOCUMENT"), typeDocument, spdx.NewMetaL(1)); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if _, err := parser.setType(uri("http://example.org/b#SPDXRef-DOCUMENT"), typeDocument, spdx.NewMetaL(2)); err != nil {
+		t.Errorf("Unexpected error for a second document: %s", err)
+	}
+	if _, err := parser.setType(uri("http://example.org/c#SPDXRef-1"), typePackage, spdx.NewMetaL(40)); err != nil {
+		t.Errorf("Unexpected error for an identifier of another document: %s", err)
+	}
+
+	// duplicate licenseId
+	for i, node := range []string{"lic1", "lic2"} {
+		parser.buffer[node] = []bufferEntry{{
+			&goraptor.Statement{Subject: blank(node), Predicate: prefix("licenseId"), Object: literal("LicenseRef-1")},
+			spdx.NewMetaL(i + 30),
+		}}
+	}
+	if _, err := parser.setType(blank("lic1"), typeExtractedLicence, nil); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if _, err := parser.setType(blank("lic2"), typeExtractedLicence, nil); err == nil {
+		t.Error("No error for duplicate licenseId.")
+	}
+}
+
+func TestTrailingReader(t *testing.T) {
+	doc := "<rdf:RDF>\n<a/>\n</rdf:RDF>\n  \n"
+	tests := map[string]int{
+		doc:                   0,
+		doc + "junk":          5,
+		doc + "\n\tjunk\n":    6,
+		"<RDF></RDF> x":       1,
+		"<a>not rdf</a> junk": 0,
+	}
+	for input, line := range tests {
+		// read one byte at a time to exercise tags split across reads
+		tr := &trailingReader{r: iotest.OneByteReader(strings.NewReader(input))}
+		out, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if tr.trailing != (line > 0) {
+			t.Errorf("Wrong trailing content detection for %#v.", input)
+		}
+		if line > 0 && tr.line != line {
+			t.Errorf("Wrong line for %#v. Found %d (expected %d)", input, tr.line, line)
+		}
+		if strings.Contains(string(out), "junk") && line > 0 {
+			t.Errorf("Trailing content passed through: %#v", string(out))
+		}
+	}
+}
+
+func TestCheckEnd(t *testing.T) {
+	readAll := func(input string, n int) *Parser {
+		p := &Parser{}
+		p.trailer = &trailingReader{r: strings.NewReader(input)}
+		p.end = &endReader{r: p.trailer}
+		if n < 0 {
+			ioutil.ReadAll(p.end)
+		} else {
+			p.end.Read(make([]byte, n))
+		}
+		p.line = 3
+		return p
+	}
+	doc := "<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n<a/>\n</rdf:RDF>\n"
+	tests := []struct {
+		p   *Parser
+		err bool
+	}{
+		{readAll(doc, -1), false},
+		{readAll("@prefix spdx: <http://spdx.org/rdf/terms#> .\n", -1), false},
+		{readAll(doc, 10), true},
+		{readAll(doc[:len(doc)-11], -1), true},
+	}
+	for i, test := range tests {
+		err := test.p.checkEnd()
+		if !test.err {
+			if err != nil {
+				t.Errorf("%d: unexpected error %s", i, err)
+			}
+			continue
+		}
+		perr, ok := err.(*spdx.ParseError)
+		if !ok || perr.Code != spdx.ErrUnexpectedEnd || perr.Meta == nil || perr.LineStart != 3 {
+			t.Errorf("%d: wrong error %#v", i, err)
+		}
+	}
+
+	p := &Parser{end: &endReader{r: iotest.TimeoutReader(strings.NewReader("x"))}}
+	ioutil.ReadAll(p.end)
+	if perr, ok := p.checkEnd().(*spdx.ParseError); !ok || perr.Code != spdx.ErrUnexpectedEnd || perr.Meta != nil {
+		t.Errorf("Wrong error for a read error: %#v", perr)
+	}
+}
+
+func TestEndReaderPipe(t *testing.T) {
+	pr, pw := io.Pipe()
+	e := &endReader{r: pr, src: pr}
+	readErr := errors.New("connection reset")
+	go func() {
+		pw.Write([]byte("abc"))
+		pw.CloseWithError(readErr)
+	}()
+	data, err := ioutil.ReadAll(e)
+	if err != nil || string(data) != "abc" {
+		t.Errorf("Wrong read: %q, %v", data, err)
+	}
+	if e.err != readErr || e.eof {
+		t.Errorf("Read error not recorded: %v", e.err)
+	}
+
+	// a writer blocked on a pipe that is not read to the end is released
+	pr, pw = io.Pipe()
+	e = &endReader{r: pr, src: pr}
+	done := make(chan error)
+	go func() {
+		_, err := pw.Write([]byte("abc"))
+		done <- err
+	}()
+	e.stop()
+	if n, err := e.Read(make([]byte, 3)); n != 0 || err != io.EOF {
+		t.Errorf("Read after stop() returned %d, %v", n, err)
+	}
+	e.close()
+	select {
+	case err := <-done:
+		if err != io.ErrClosedPipe {
+			t.Errorf("Wrong write error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Writer still blocked on the pipe.")
+	}
+}
+
+func TestStrictUnknownProperty(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("unknownProperty"), Object: literal("buffered")},
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("anotherUnknown"), Object: literal("direct")},
+		{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("pkg-name")},
+	}
+
+	parser := &Parser{
+		Strict: true,
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	var err error
+	for i, stm := range stms {
+		if err = parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		t.Error("No error for unknown property in strict mode.")
+	}
+
+	parser = &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Errorf("Unexpected error in lenient mode: %s", err)
+		}
+	}
+	warnings := parser.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings but found %d: %+v", len(warnings), warnings)
+	}
+	if warnings[0].LineStart != 1 || warnings[1].LineStart != 3 {
+		t.Errorf("Wrong warning lines: %d and %d", warnings[0].LineStart, warnings[1].LineStart)
+	}
+	pkg, err := parser.reqPackage(blank("pkg"))
+	if err != nil || pkg.Name.Val != "pkg-name" {
+		t.Errorf("Parsing did not continue after unknown properties: %+v (%s)", pkg, err)
+	}
+}
+
+func TestVerificationCodeNestedValue(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	value := "d6a770ba38583ed4bb4525bd96e50461655d2758"
+	stms := []*goraptor.Statement{
+		{Subject: blank("vc"), Predicate: prefix("ns:type"), Object: typeVerificationCode},
+		{Subject: blank("val"), Predicate: prefix("algorithm"), Object: uri("http://spdx.org/rdf/terms#checksumAlgorithm_sha1")},
+		{Subject: blank("vc"), Predicate: prefix("packageVerificationCodeValue"), Object: blank("val")},
+		{Subject: blank("val"), Predicate: prefix("checksumValue"), Object: literal(value)},
+		{Subject: blank("vc"), Predicate: prefix("packageVerificationCodeExcludedFile"), Object: literal("excluded.txt")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Errorf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	vc, err := parser.reqVerificationCode(blank("vc"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if vc.Value.Val != value || vc.Value.Meta.LineStart != 4 {
+		t.Errorf("Wrong nested verification code value: %#v", vc.Value)
+	}
+	if len(vc.ExcludedFiles) != 1 {
+		t.Errorf("Wrong excluded files: %#v", vc.ExcludedFiles)
+	}
+
+	// flat literal form
+	bldr := parser.verificationCodeMap(new(spdx.VerificationCode))
+	if err := bldr.apply(prefix("packageVerificationCodeValue"), literal(value), nil); err != nil {
+		t.Errorf("Unexpected error for literal value: %s", err)
+	}
+	if vc := bldr.ptr.(*spdx.VerificationCode); vc.Value.Val != value {
+		t.Errorf("Wrong literal verification code value: %#v", vc.Value)
+	}
+
+	// value node typed spdx:Checksum, before and after it is referenced
+	typed := [][]*goraptor.Statement{
+		{
+			{Subject: blank("val"), Predicate: prefix("ns:type"), Object: typeChecksum},
+			{Subject: blank("val"), Predicate: prefix("checksumValue"), Object: literal(value)},
+			{Subject: blank("vc"), Predicate: prefix("ns:type"), Object: typeVerificationCode},
+			{Subject: blank("vc"), Predicate: prefix("packageVerificationCodeValue"), Object: blank("val")},
+			{Subject: blank("val"), Predicate: prefix("algorithm"), Object: uri("http://spdx.org/rdf/terms#checksumAlgorithm_sha1")},
+		},
+		{
+			{Subject: blank("vc"), Predicate: prefix("ns:type"), Object: typeVerificationCode},
+			{Subject: blank("vc"), Predicate: prefix("packageVerificationCodeValue"), Object: blank("val")},
+			{Subject: blank("val"), Predicate: prefix("checksumValue"), Object: literal(value)},
+			{Subject: blank("val"), Predicate: prefix("ns:type"), Object: typeChecksum},
+			{Subject: blank("val"), Predicate: prefix("algorithm"), Object: uri("http://spdx.org/rdf/terms#checksumAlgorithm_sha1")},
+		},
+	}
+	for i, stms := range typed {
+		parser := &Parser{
+			index:  make(map[string]*builder),
+			buffer: make(map[string][]bufferEntry),
+			Strict: true,
+		}
+		for j, stm := range stms {
+			if err := parser.processTruple(stm, spdx.NewMetaL(j+1)); err != nil {
+				t.Fatalf("%d: unexpected error while processing %#v: %s", i, *stm, err)
+			}
+		}
+		if vc := parser.index["vc"].ptr.(*spdx.VerificationCode); vc.Value.Val != value {
+			t.Errorf("%d: wrong verification code value in a checksum: %#v", i, vc.Value)
+		}
+	}
+
+	parser = &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms = []*goraptor.Statement{
+		{Subject: blank("val"), Predicate: prefix("ns:type"), Object: typeChecksum},
+		{Subject: blank("val"), Predicate: prefix("algorithm"), Object: uri("http://spdx.org/rdf/terms#checksumAlgorithm_md5")},
+		{Subject: blank("vc"), Predicate: prefix("ns:type"), Object: typeVerificationCode},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	stm := &goraptor.Statement{Subject: blank("vc"), Predicate: prefix("packageVerificationCodeValue"), Object: blank("val")}
+	if perr, ok := parser.processTruple(stm, spdx.NewMetaL(4)).(*spdx.ParseError); !ok || perr.Code != spdx.ErrInvalidValue {
+		t.Errorf("Wrong error for a MD5 verification code value: %#v", perr)
+	}
+}
+
+func TestLicenceWithException(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	gpl := uri(licenceUri + "GPL-2.0")
+	stms := []*goraptor.Statement{
+		{Subject: blank("with"), Predicate: prefix("ns:type"), Object: typeWithException},
+		{Subject: blank("with"), Predicate: prefix("member"), Object: gpl},
+		{Subject: blank("exc"), Predicate: prefix("licenseExceptionId"), Object: literal("Classpath-exception-2.0")},
+		{Subject: blank("exc"), Predicate: prefix("name"), Object: literal("Classpath exception 2.0")},
+		{Subject: blank("exc"), Predicate: prefix("ns:type"), Object: typeLicenceException},
+		{Subject: blank("with"), Predicate: prefix("licenseException"), Object: blank("exc")},
+
+		// abstract licence set promoted to a WithException
+		{Subject: blank("set"), Predicate: prefix("ns:type"), Object: typeAnyLicence},
+		{Subject: blank("set"), Predicate: prefix("member"), Object: gpl},
+		{Subject: blank("set"), Predicate: prefix("ns:type"), Object: typeWithException},
+		{Subject: blank("set"), Predicate: prefix("licenseException"), Object: blank("exc")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+
+	exception := &spdx.LicenceException{Id: spdx.Str("Classpath-exception-2.0", nil), Name: spdx.Str("Classpath exception 2.0", nil)}
+	expected := spdx.NewWithException(nil, spdx.NewLicence("GPL-2.0", nil), exception)
+	for _, node := range []string{"with", "set"} {
+		lic, err := parser.reqAnyLicence(blank(node))
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if !spdx.SameLicence(lic, expected) {
+			t.Errorf("Wrong licence with exception for %s: %#v", node, lic)
+		}
+	}
+
+	// licence URI with an exception
+	lic, err := parser.reqAnyLicence(uri(licenceUri + "GPL-2.0%20WITH%20Classpath-exception-2.0"))
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if with, ok := lic.(spdx.WithException); !ok || with.LicenceId() != "GPL-2.0 with Classpath-exception-2.0" {
+		t.Errorf("Licence URI with exception not wrapped: %#v", lic)
+	}
+}
+
+func TestMaxUnknownRatio(t *testing.T) {
+	parser := &Parser{
+		MaxUnknownRatio: 1,
+		index:           make(map[string]*builder),
+		buffer:          make(map[string][]bufferEntry),
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("pkg")},
+		{Subject: blank("pkg"), Predicate: uri("http://example.org/title"), Object: literal("pkg")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	}
+	if err := parser.checkUnknownRatio(); err != nil {
+		t.Errorf("Unexpected error below the threshold: %s", err)
+	}
+
+	garbage := []string{"creator", "date", "subject", "publisher"}
+	for i, pred := range garbage {
+		stm := &goraptor.Statement{Subject: blank("pkg"), Predicate: uri("http://purl.org/dc/elements/1.1/" + pred), Object: literal("x")}
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+4)); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	}
+	if err := parser.checkUnknownRatio(); err == nil {
+		t.Error("No error for mostly unknown properties.")
+	}
+
+	parser.MaxUnknownRatio = 0
+	if err := parser.checkUnknownRatio(); err != nil {
+		t.Errorf("Unexpected error with the check disabled: %s", err)
+	}
+}
+
+func TestUnknownTypeError(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	stm := &goraptor.Statement{
+		Subject:   uri("http://example.org/doc#SPDXRef-Bogus"),
+		Predicate: prefix("ns:type"),
+		Object:    prefix("BogusType"),
+	}
+	err := parser.processTruple(stm, spdx.NewMetaL(42))
+	if err == nil {
+		t.Fatal("No error for unknown type.")
+	}
+	msg := err.Error()
+	for _, part := range []string{"SPDXRef-Bogus", "BogusType", "42"} {
+		if !strings.Contains(msg, part) {
+			t.Errorf("Error %#v does not contain %#v", msg, part)
+		}
+	}
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.LineStart != 42 {
+		t.Errorf("Wrong error meta: %#v", err)
+	}
+}
+
+func TestInferTypes(t *testing.T) {
+	parser := &Parser{
+		index:      make(map[string]*builder),
+		buffer:     make(map[string][]bufferEntry),
+		InferTypes: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("f"), Predicate: prefix("fileName"), Object: literal("main.go")},
+		{Subject: blank("f"), Predicate: prefix("fileType"), Object: prefix("fileType_source")},
+		{Subject: blank("x"), Predicate: prefix("copyrightText"), Object: literal("NONE")},
+	}
+	for _, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := parser.inferTypes(); err != nil {
+		t.Fatal(err)
+	}
+
+	bldr, ok := parser.index[termStr(blank("f"))]
+	if !ok || !bldr.t.Equals(typeFile) {
+		t.Fatalf("File type not inferred: %#v", bldr)
+	}
+	file := bldr.ptr.(*spdx.File)
+	if file.Name.Val != "main.go" || file.Type.Val != "SOURCE" {
+		t.Errorf("Wrong file: %#v", file)
+	}
+
+	// copyrightText is supported by both File and Package
+	if _, ok := parser.index[termStr(blank("x"))]; ok {
+		t.Error("Type inferred for an ambiguous node.")
+	}
+	if len(parser.buffer[termStr(blank("x"))]) != 1 {
+		t.Error("Ambiguous node removed from the buffer.")
+	}
+}
+
+func TestObsoleteNamespace(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	old := func(term string) *goraptor.Uri { return uri(obsoleteBaseUri + term) }
+	stms := []*goraptor.Statement{
+		{Subject: blank("f"), Predicate: old("fileName"), Object: literal("main.go")},
+		{Subject: blank("f"), Predicate: uri_nstype, Object: old("File")},
+		{Subject: blank("f"), Predicate: old("fileType"), Object: old("fileType_source")},
+	}
+	for _, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bldr, ok := parser.index[termStr(blank("f"))]
+	if !ok || !bldr.t.Equals(typeFile) {
+		t.Fatalf("File not parsed: %#v", bldr)
+	}
+	file := bldr.ptr.(*spdx.File)
+	if file.Name.Val != "main.go" || file.Type.Val != "SOURCE" {
+		t.Errorf("Wrong file: %#v", file)
+	}
+	if len(parser.Warnings()) != 1 {
+		t.Errorf("Expected one warning, found %d.", len(parser.Warnings()))
+	}
+}
+
+func TestUnknownLicenceType(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	orLater := prefix("OrLaterOperator")
+	stms := []*goraptor.Statement{
+		{Subject: blank("lic"), Predicate: prefix("ns:type"), Object: orLater},
+		{Subject: blank("lic"), Predicate: prefix("member"), Object: uri(licenceUri + "GPL-2.0")},
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file"), Predicate: prefix("licenseConcluded"), Object: blank("lic")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+
+	file := parser.index[termStr(blank("file"))].ptr.(*spdx.File)
+	lic, ok := file.LicenceConcluded.(*spdx.UnknownLicence)
+	if !ok {
+		t.Fatalf("Wrong licence: %#v", file.LicenceConcluded)
+	}
+	if lic.Type.Val != termStr(orLater) || len(lic.Members) != 1 || lic.Members[0].LicenceId() != "GPL-2.0" {
+		t.Errorf("Wrong unknown licence: %#v", lic)
+	}
+
+	// other unknown types are still errors
+	stm := &goraptor.Statement{Subject: blank("x"), Predicate: prefix("ns:type"), Object: prefix("Vulnerability")}
+	if err := parser.processTruple(stm, nil); err == nil {
+		t.Error("No error for an unknown type that is not a licence.")
+	}
+}
+
+func TestParserFormat(t *testing.T) {
+	tests := map[string]string{
+		"rdf":            FormatGuess,
+		FormatGuess:      FormatGuess,
+		FormatRDFXML:     FormatRDFXML,
+		Fmt_rdfxmlAbbrev: FormatRDFXML,
+		FormatNTriples:   FormatNTriples,
+		FormatTurtle:     FormatTurtle,
+	}
+	for in, expected := range tests {
+		if res, err := parserFormat(in); err != nil || res != expected {
+			t.Errorf("Format %s: found %#v, %v (expected %#v)", in, res, err, expected)
+		}
+	}
+
+	parser, err := NewParser(strings.NewReader(""), Fmt_dot)
+	if parser != nil {
+		t.Error("Parser created for an unsupported format.")
+	}
+	if ierr, ok := err.(*InitError); !ok || ierr.Format != Fmt_dot || !strings.Contains(ierr.Error(), Fmt_dot) {
+		t.Errorf("Wrong error for an unsupported format: %v", err)
+	}
+}
+
+func TestRetainLocators(t *testing.T) {
+	parser := &Parser{
+		index:          make(map[string]*builder),
+		buffer:         make(map[string][]bufferEntry),
+		RetainLocators: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("pkg")},
+	}
+	locs := []*goraptor.Locator{
+		{File: "doc.rdf", Line: 3, Column: 5, Byte: 40},
+		{File: "doc.rdf", Line: 4, Column: 7, Byte: 62},
+	}
+	for i := range stms {
+		if err := parser.readStatement(stms[i], locs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(parser.Statements()) != len(stms) || len(parser.Locators()) != len(locs) {
+		t.Fatalf("Wrong number of statements or locators: %d, %d", len(parser.Statements()), len(parser.Locators()))
+	}
+	for i := range stms {
+		if parser.Statements()[i] != stms[i] {
+			t.Errorf("Wrong statement %d: %#v", i, parser.Statements()[i])
+		}
+		if parser.Locators()[i] != *locs[i] {
+			t.Errorf("Wrong locator %d: %#v", i, parser.Locators()[i])
+		}
+	}
+	if pkg := parser.index[termStr(blank("pkg"))].ptr.(*spdx.Package); pkg.Name.Meta.LineStart != 4 {
+		t.Errorf("Wrong meta: %#v", pkg.Name.Meta)
+	}
+}
+
+func TestBlankNodeIds(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	stms := []*goraptor.Statement{
+		// abstract set promoted to a conjunctive set
+		{Subject: blank("set1"), Predicate: prefix("ns:type"), Object: typeAnyLicence},
+		{Subject: blank("set1"), Predicate: prefix("member"), Object: uri(licenceUri + "MIT")},
+		{Subject: blank("set1"), Predicate: prefix("ns:type"), Object: typeConjunctiveSet},
+		{Subject: blank("set2"), Predicate: prefix("ns:type"), Object: typeDisjunctiveSet},
+		{Subject: blank("lic"), Predicate: prefix("ns:type"), Object: typeExtractedLicence},
+		{Subject: blank("lic"), Predicate: prefix("licenseId"), Object: literal("LicenseRef-1")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if set := parser.index["set1"].ptr.(*spdx.ConjunctiveLicenceSet); set.NodeId != "set1" {
+		t.Errorf("Wrong promoted set node ID: %#v", set.NodeId)
+	}
+	if set := parser.index["set2"].ptr.(*spdx.DisjunctiveLicenceSet); set.NodeId != "set2" {
+		t.Errorf("Wrong set node ID: %#v", set.NodeId)
+	}
+	if lic := parser.index["lic"].ptr.(*spdx.ExtractedLicence); lic.NodeId != "lic" {
+		t.Errorf("Wrong extracted licence node ID: %#v", lic.NodeId)
+	}
+}
+
+func TestExternalDocumentRef(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("externalDocumentRef"), Object: blank("ref")},
+		{Subject: blank("ref"), Predicate: prefix("externalDocumentId"), Object: literal("DocumentRef-other")},
+		{Subject: blank("ref"), Predicate: prefix("spdxDocument"), Object: literal("http://example.org/other.spdx")},
+		{Subject: blank("ref"), Predicate: prefix("checksum"), Object: blank("cksum")},
+		{Subject: blank("cksum"), Predicate: prefix("algorithm"), Object: prefix("checksumAlgorithm_sha1")},
+		{Subject: blank("cksum"), Predicate: prefix("checksumValue"), Object: literal("d6a770ba38583ed4bb4525bd96e50461655d2759")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+
+	expected := &spdx.ExternalDocumentRef{
+		Id:       spdx.Str("DocumentRef-other", nil),
+		Document: spdx.Str("http://example.org/other.spdx", nil),
+		Checksum: &spdx.Checksum{Algo: spdx.Str("SHA1", nil), Value: spdx.Str("d6a770ba38583ed4bb4525bd96e50461655d2759", nil)},
+	}
+	refs := parser.doc.ExternalDocumentRefs
+	if len(refs) != 1 || !refs[0].Equal(expected) {
+		t.Errorf("Wrong external document references: %#v", refs)
+	}
+}
+
+// The checksum of an external document reference is only referenced by it,
+// and its statements come before the type of the reference: they are buffered.
+func TestExternalDocumentRefChecksumBuffered(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("ref"), Predicate: prefix("checksum"), Object: blank("cksum")},
+		{Subject: blank("cksum"), Predicate: prefix("algorithm"), Object: prefix("checksumAlgorithm_sha1")},
+		{Subject: blank("cksum"), Predicate: prefix("checksumValue"), Object: literal("d6a770ba38583ed4bb4525bd96e50461655d2759")},
+		{Subject: blank("ref"), Predicate: prefix("externalDocumentId"), Object: literal("DocumentRef-other")},
+		{Subject: blank("ref"), Predicate: prefix("ns:type"), Object: typeExternalDocumentRef},
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("externalDocumentRef"), Object: blank("ref")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+
+	refs := parser.doc.ExternalDocumentRefs
+	if len(refs) != 1 || refs[0].Id.Val != "DocumentRef-other" {
+		t.Fatalf("Wrong external document references: %#v", refs)
+	}
+	expected := &spdx.Checksum{Algo: spdx.Str("SHA1", nil), Value: spdx.Str("d6a770ba38583ed4bb4525bd96e50461655d2759", nil)}
+	if !refs[0].Checksum.Equal(expected) {
+		t.Errorf("Wrong checksum: %#v", refs[0].Checksum)
+	}
+	if refs[0].Checksum != parser.index["cksum"].ptr {
+		t.Error("The checksum is not the one built for its node.")
+	}
+	if len(parser.buffer) != 0 || parser.buffered != 0 {
+		t.Errorf("Statements left in the buffer: %#v", parser.buffer)
+	}
+}
+
+func TestInlinedCreationInfo(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("creator"), Object: literal("Tool: spdx-go")},
+		{Subject: blank("doc"), Predicate: prefix("created"), Object: literal("2014-08-26T10:30:00Z")},
+	}
+
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	cri := parser.doc.CreationInfo
+	if cri == nil {
+		t.Fatal("No creation info.")
+	}
+	if len(cri.Creator) != 1 || cri.Creator[0].V() != "Tool: spdx-go" || cri.Created.V() != "2014-08-26T10:30:00Z" {
+		t.Errorf("Wrong creation info: %#v", cri)
+	}
+
+	parser = &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	if err := parser.processTruple(stms[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.processTruple(stms[1], nil); err == nil {
+		t.Error("Inlined creation info accepted in Strict mode.")
+	}
+
+	// inlined properties are defined only once
+	parser = &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	if err := parser.processTruple(stms[2], spdx.NewMetaL(4)); err == nil {
+		t.Error("No error for created inlined twice.")
+	}
+
+	// the CreationInfo node is kept, with a warning, over inlined properties
+	// read before or after it
+	node := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("creationInfo"), Object: blank("cri")},
+		{Subject: blank("cri"), Predicate: prefix("ns:type"), Object: typeCreationInfo},
+		{Subject: blank("cri"), Predicate: prefix("created"), Object: literal("2015-01-01T00:00:00Z")},
+	}
+	for _, order := range [][]*goraptor.Statement{append(stms[:3:3], node...), append(append(stms[:1:1], node...), stms[1:]...)} {
+		parser = &Parser{
+			index:  make(map[string]*builder),
+			buffer: make(map[string][]bufferEntry),
+		}
+		for i, stm := range order {
+			if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+				t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+			}
+		}
+		cri := parser.doc.CreationInfo
+		if cri != parser.index["cri"].ptr || cri.Created.V() != "2015-01-01T00:00:00Z" || len(cri.Creator) != 0 {
+			t.Errorf("Wrong creation info: %#v", cri)
+		}
+		if len(parser.warnings) == 0 {
+			t.Error("No warning for creation info both inlined and in a node.")
+		}
+	}
+}
+
+func TestRelationshipType(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("relationship"), Object: blank("rel")},
+		{Subject: blank("rel"), Predicate: prefix("relationshipType"), Object: prefix("relationshipType_dependsOn")},
+		{Subject: blank("rel"), Predicate: prefix("relatedSpdxElement"), Object: uri("http://example.org/doc#SPDXRef-lib")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	expected := &spdx.Relationship{Type: spdx.Str("DEPENDS_ON", nil), Related: spdx.Str("http://example.org/doc#SPDXRef-lib", nil)}
+	if len(pkg.Relationships) != 1 || !pkg.Relationships[0].Equal(expected) {
+		t.Errorf("Wrong relationships: %#v", pkg.Relationships)
+	}
+
+	invalid := &goraptor.Statement{Subject: blank("rel2"), Predicate: prefix("relationshipType"), Object: prefix("relationshipType_dependsOnn")}
+	if err := parser.processTruple(&goraptor.Statement{Subject: blank("rel2"), Predicate: prefix("ns:type"), Object: typeRelationship}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.processTruple(invalid, nil); err == nil {
+		t.Error("No error for an unknown relationship type in Strict mode.")
+	}
+	parser.Strict = false
+	if err := parser.processTruple(invalid, nil); err != nil || len(parser.Warnings()) != 1 {
+		t.Errorf("Unknown relationship type not reported as a warning: %v", err)
+	}
+}
+
+func TestExternalRef(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("externalRef"), Object: blank("ref")},
+		{Subject: blank("ref"), Predicate: prefix("referenceCategory"), Object: prefix("referenceCategory_packageManager")},
+		{Subject: blank("ref"), Predicate: prefix("referenceType"), Object: uri(referenceTypeUri + "maven-central")},
+		{Subject: blank("ref"), Predicate: prefix("referenceLocator"), Object: literal("org.apache.tomcat:tomcat:9.0.0.M4")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	expected := &spdx.ExternalRef{
+		Category: spdx.Str("PACKAGE-MANAGER", nil),
+		Type:     spdx.Str("maven-central", nil),
+		Locator:  spdx.Str("org.apache.tomcat:tomcat:9.0.0.M4", nil),
+	}
+	if len(pkg.ExternalRefs) != 1 || !pkg.ExternalRefs[0].Equal(expected) {
+		t.Errorf("Wrong external references: %#v", pkg.ExternalRefs)
+	}
+
+	stms = []*goraptor.Statement{
+		{Subject: blank("ref2"), Predicate: prefix("ns:type"), Object: typeExternalRef},
+		{Subject: blank("ref2"), Predicate: prefix("referenceCategory"), Object: prefix("referenceCategory_website")},
+	}
+	if err := parser.processTruple(stms[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.processTruple(stms[1], nil); err == nil {
+		t.Error("No error for an unknown reference category.")
+	}
+}
+
+func TestParseErrorCodes(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	tests := []struct {
+		stm  *goraptor.Statement
+		code spdx.ErrorCode
+	}{
+		{&goraptor.Statement{Subject: blank("x"), Predicate: prefix("ns:type"), Object: prefix("Bogus")}, spdx.ErrUnknownType},
+		{&goraptor.Statement{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage}, spdx.ErrOther},
+		{&goraptor.Statement{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typeFile}, spdx.ErrIncompatibleTypes},
+		{&goraptor.Statement{Subject: blank("pkg"), Predicate: prefix("fileName"), Object: literal("f")}, spdx.ErrPropertyNotSupported},
+		{&goraptor.Statement{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("a")}, spdx.ErrOther},
+		{&goraptor.Statement{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("b")}, spdx.ErrAlreadyDefined},
+	}
+	for i, test := range tests {
+		err := parser.processTruple(test.stm, spdx.NewMetaL(i+1))
+		if test.code == spdx.ErrOther {
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			continue
+		}
+		perr, ok := err.(*spdx.ParseError)
+		if !ok || perr.Code != test.code {
+			t.Errorf("%d: found error %#v (expected code %d)", i, err, test.code)
+		}
+	}
+}
+
+func TestDedupFiles(t *testing.T) {
+	newFile := func(name, sha1 string) *spdx.File {
+		return &spdx.File{
+			Name:     spdx.Str(name, nil),
+			Checksum: &spdx.Checksum{Algo: spdx.Str("SHA1", nil), Value: spdx.Str(sha1, nil)},
+		}
+	}
+	shared1 := newFile("LICENSE", "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12")
+	shared2 := newFile("LICENSE", "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12")
+	other := newFile("LICENSE", "de9f2c7fd25e1b3afad3e85a0bd17d9b100db4b3")
+	parser := &Parser{
+		DedupFiles: true,
+		doc: &spdx.Document{
+			Packages: []*spdx.Package{
+				{Files: []*spdx.File{shared1}},
+				{Files: []*spdx.File{shared2, other}},
+			},
+		},
+	}
+	parser.dedupFiles()
+
+	pkgs := parser.doc.Packages
+	if pkgs[0].Files[0] != shared1 || pkgs[1].Files[0] != shared1 {
+		t.Error("Shared file not merged.")
+	}
+	if pkgs[1].Files[1] != other {
+		t.Error("Files with different checksums merged.")
+	}
+}
+
+func TestSetProgress(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	var calls []int
+	parser.SetProgress(func(n int) { calls = append(calls, n) })
+	for i := 0; i < 2*progressInterval+1; i++ {
+		stm := &goraptor.Statement{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("pkg")}
+		if err := parser.readStatement(stm, &goraptor.Locator{Line: i + 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(calls) != 2 || calls[0] != progressInterval || calls[1] != 2*progressInterval {
+		t.Errorf("Wrong progress calls: %v", calls)
+	}
+}
+
+func TestMaxSetMembers(t *testing.T) {
+	parser := &Parser{
+		index:         make(map[string]*builder),
+		buffer:        make(map[string][]bufferEntry),
+		MaxSetMembers: 2,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("set"), Predicate: prefix("ns:type"), Object: typeDisjunctiveSet},
+		{Subject: blank("set"), Predicate: prefix("member"), Object: uri(licenceUri + "MIT")},
+		{Subject: blank("set"), Predicate: prefix("member"), Object: uri(licenceUri + "ISC")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	stm := &goraptor.Statement{Subject: blank("set"), Predicate: prefix("member"), Object: uri(licenceUri + "Zlib")}
+	err := parser.processTruple(stm, spdx.NewMetaL(4))
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrLimitExceeded || perr.LineStart != 4 {
+		t.Errorf("Wrong error for too many set members: %#v", err)
+	}
+}
+
+func TestDedupSetMembers(t *testing.T) {
+	for _, dedup := range []bool{false, true} {
+		parser := &Parser{
+			index:           make(map[string]*builder),
+			buffer:          make(map[string][]bufferEntry),
+			Strict:          true,
+			DedupSetMembers: dedup,
+		}
+		if dedup {
+			// the dropped duplicates don't count against the limit
+			parser.MaxSetMembers = 2
+		}
+		stms := []*goraptor.Statement{
+			{Subject: blank("ref"), Predicate: prefix("ns:type"), Object: typeExtractedLicence},
+			{Subject: blank("ref"), Predicate: prefix("licenseId"), Object: literal("LicenseRef-1")},
+			{Subject: blank("set"), Predicate: prefix("ns:type"), Object: typeConjunctiveSet},
+			{Subject: blank("set"), Predicate: prefix("member"), Object: uri(licenceUri + "MIT")},
+			{Subject: blank("set"), Predicate: prefix("member"), Object: blank("ref")},
+			{Subject: blank("set"), Predicate: prefix("member"), Object: uri(licenceUri + "MIT")},
+			{Subject: blank("set"), Predicate: prefix("member"), Object: blank("ref")},
+		}
+		for i, stm := range stms {
+			if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+				t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+			}
+		}
+		set := parser.index["set"].ptr.(*spdx.ConjunctiveLicenceSet)
+		expected := "(MIT and LicenseRef-1 and MIT and LicenseRef-1)"
+		if dedup {
+			expected = "(MIT and LicenseRef-1)"
+		}
+		if set.V() != expected {
+			t.Errorf("Wrong set with DedupSetMembers=%t: %s", dedup, set.V())
+		}
+	}
+}
+
+func TestArtifactOfRevisionAndWiki(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	project := uri("http://example.org/project")
+	stms := []*goraptor.Statement{
+		{Subject: project, Predicate: prefix("ns:type"), Object: typeArtifactOf},
+		{Subject: project, Predicate: prefix("doap:name"), Object: literal("project")},
+		{Subject: project, Predicate: prefix("doap:revision"), Object: literal("1.2")},
+		{Subject: project, Predicate: prefix("doap:wiki"), Object: uri("http://example.org/project/wiki")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	artif := parser.index[termStr(project)].ptr.(*spdx.ArtifactOf)
+	if artif.Revision.Val != "1.2" || artif.Wiki.Val != "http://example.org/project/wiki" {
+		t.Errorf("Wrong artifact: %#v", artif)
+	}
+}
+
+func TestRelationshipToDocument(t *testing.T) {
+	docNode := uri("http://example.org/doc#SPDXRef-DOCUMENT")
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("relationship"), Object: blank("rel")},
+		{Subject: blank("rel"), Predicate: prefix("relationshipType"), Object: prefix("relationshipType_describedBy")},
+		{Subject: blank("rel"), Predicate: prefix("relatedSpdxElement"), Object: docNode},
+		{Subject: docNode, Predicate: prefix("ns:type"), Object: typeDocument},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	parser.resolveRelated()
+
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	if len(pkg.Relationships) != 1 {
+		t.Fatalf("Wrong relationships: %#v", pkg.Relationships)
+	}
+	if doc, ok := pkg.Relationships[0].Element.(*spdx.Document); !ok || doc != parser.doc {
+		t.Errorf("Relationship element is not the document: %#v", pkg.Relationships[0].Element)
+	}
+}
+
+func TestDocumentId(t *testing.T) {
+	docNode := uri("http://example.org/doc#SPDXRef-DOCUMENT")
+	stms := []*goraptor.Statement{
+		{Subject: docNode, Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: docNode, Predicate: prefix("relationship"), Object: blank("rel")},
+		{Subject: blank("rel"), Predicate: prefix("relationshipType"), Object: prefix("relationshipType_describes")},
+		{Subject: blank("rel"), Predicate: prefix("relatedSpdxElement"), Object: docNode},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	parser.resolveRelated()
+
+	doc := parser.doc
+	if doc.Id.Val != "SPDXRef-DOCUMENT" || doc.Id.Meta == nil || doc.Id.Meta.LineStart != 1 {
+		t.Errorf("Wrong document ID: %#v", doc.Id)
+	}
+	if len(doc.Relationships) != 1 || doc.Relationships[0].Element != doc {
+		t.Fatalf("Wrong relationships: %#v", doc.Relationships)
+	}
+	if nodeId(uri(doc.Relationships[0].Related.Val)) != doc.Id.Val {
+		t.Errorf("Related element %s is not the document ID.", doc.Relationships[0].Related.Val)
+	}
+}
+
+func TestManyDocuments(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc1"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc1"), Predicate: prefix("describesPackage"), Object: blank("pkg1")},
+		{Subject: blank("doc2"), Predicate: prefix("describesPackage"), Object: blank("pkg2")},
+		{Subject: blank("doc2"), Predicate: prefix("referencesFile"), Object: blank("file")},
+		{Subject: blank("doc2"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc1"), Predicate: prefix("specVersion"), Object: literal("SPDX-1.2")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+
+	docs := parser.docs
+	if len(docs) != 2 {
+		t.Fatalf("Found %d documents (expected 2).", len(docs))
+	}
+	pkg1 := parser.index["pkg1"].ptr.(*spdx.Package)
+	pkg2 := parser.index["pkg2"].ptr.(*spdx.Package)
+	file := parser.index["file"].ptr.(*spdx.File)
+	if len(docs[0].Packages) != 1 || docs[0].Packages[0] != pkg1 || len(docs[0].Files) != 0 || docs[0].SpecVersion.Val != "SPDX-1.2" {
+		t.Errorf("Wrong first document: %#v", docs[0])
+	}
+	if len(docs[1].Packages) != 1 || docs[1].Packages[0] != pkg2 || len(docs[1].Files) != 1 || docs[1].Files[0] != file {
+		t.Errorf("Wrong second document: %#v", docs[1])
+	}
+}
+
+func TestManyUriDocuments(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	doc1, doc2 := uri("http://a.example/doc#SPDXRef-DOCUMENT"), uri("http://b.example/doc#SPDXRef-DOCUMENT")
+	stms := []*goraptor.Statement{
+		{Subject: doc1, Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: doc1, Predicate: prefix("describesPackage"), Object: uri("http://a.example/doc#SPDXRef-1")},
+		{Subject: uri("http://a.example/doc#SPDXRef-1"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: doc2, Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: doc2, Predicate: prefix("describesPackage"), Object: uri("http://b.example/doc#SPDXRef-1")},
+		{Subject: uri("http://b.example/doc#SPDXRef-1"), Predicate: prefix("ns:type"), Object: typePackage},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+
+	docs := parser.docs
+	if len(docs) != 2 {
+		t.Fatalf("Found %d documents (expected 2).", len(docs))
+	}
+	for i, doc := range docs {
+		if doc.Id.Val != "SPDXRef-DOCUMENT" || len(doc.Packages) != 1 {
+			t.Errorf("Wrong document %d: %#v", i+1, doc)
+		}
+	}
+}
+
+func TestInternLicence(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	// the package refers to MIT with another node than the files
+	stms := []*goraptor.Statement{
+		{Subject: blank("f1"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("f1"), Predicate: prefix("licenseConcluded"), Object: uri(licenceUri + "MIT")},
+		{Subject: blank("f2"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("f2"), Predicate: prefix("licenseConcluded"), Object: uri(licenceUri + "MIT")},
+		{Subject: blank("f2"), Predicate: prefix("licenseInfoInFile"), Object: uri(licenceUri + "ISC")},
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("licenseDeclared"), Object: uri("MIT")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	f1 := parser.index["f1"].ptr.(*spdx.File)
+	f2 := parser.index["f2"].ptr.(*spdx.File)
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+
+	lic, ok := f1.LicenceConcluded.(*spdx.Licence)
+	if !ok {
+		t.Fatalf("Wrong licence: %#v", f1.LicenceConcluded)
+	}
+	if f2.LicenceConcluded != lic || pkg.LicenceDeclared != lic {
+		t.Errorf("Licence with the same ID not shared: %#v, %#v", f2.LicenceConcluded, pkg.LicenceDeclared)
+	}
+	if f2.LicenceInfoInFile[0] == lic {
+		t.Error("Licences with different IDs interned together.")
+	}
+	// the licences are only referenced, so the first one has no metadata
+	if later := parser.internLicence(&spdx.Licence{ValueStr: spdx.Str("MIT", spdx.NewMetaL(9))}); later != lic || later.Meta != nil {
+		t.Errorf("Interned licence doesn't keep the first meta: %#v", later.Meta)
+	}
+}
+
+func TestOnFileVerify(t *testing.T) {
+	var verified []string
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		OnFileVerify: func(f *spdx.File) (bool, error) {
+			verified = append(verified, f.Name.Val)
+			return f.Name.Val != "bad.c", nil
+		},
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("f1"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("f1"), Predicate: prefix("fileName"), Object: literal("good.c")},
+		{Subject: blank("f2"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("f2"), Predicate: prefix("fileName"), Object: literal("bad.c")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := parser.verifyFiles(); err != nil {
+		t.Fatalf("Unexpected error in lenient mode: %s", err)
+	}
+	if len(verified) != 2 || verified[0] != "good.c" || verified[1] != "bad.c" {
+		t.Errorf("Wrong files verified: %v", verified)
+	}
+	if len(parser.Warnings()) != 1 || parser.Warnings()[0].Code != spdx.ErrVerification {
+		t.Errorf("Wrong warnings: %v", parser.Warnings())
+	}
+
+	parser.Strict = true
+	if err := parser.verifyFiles(); err == nil {
+		t.Error("No error for a file that failed verification in Strict mode.")
+	}
+}
+
+func TestOnFileVerifyManyDocuments(t *testing.T) {
+	var verified []string
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+		OnFileVerify: func(f *spdx.File) (bool, error) {
+			verified = append(verified, f.Name.Val)
+			return true, nil
+		},
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc1"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc1"), Predicate: prefix("referencesFile"), Object: blank("f1")},
+		{Subject: blank("f1"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("f1"), Predicate: prefix("fileName"), Object: literal("a.c")},
+		{Subject: blank("doc2"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc2"), Predicate: prefix("referencesFile"), Object: blank("f2")},
+		{Subject: blank("f2"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("f2"), Predicate: prefix("fileName"), Object: literal("b.c")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatal(err)
+		}
+		// the first file is verified when the second document starts
+		expected := 0
+		if i >= 4 {
+			expected = 1
+		}
+		if len(verified) != expected {
+			t.Fatalf("Files verified after statement %d: %v", i+1, verified)
+		}
+	}
+
+	if err := parser.verifyFiles(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(verified) != 2 || verified[0] != "a.c" || verified[1] != "b.c" {
+		t.Errorf("Wrong files verified: %v", verified)
+	}
+}
+
+func TestElementLineRange(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("pkg")},
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("pkg"), Predicate: prefix("versionInfo"), Object: literal("1.0")},
+		{Subject: blank("file"), Predicate: prefix("fileName"), Object: literal("f")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	if pkg.Meta.LineStart != 1 || pkg.Meta.LineEnd != 4 {
+		t.Errorf("Wrong package line range: %#v", pkg.Meta)
+	}
+	if pkg.Name.Meta.LineStart != 2 || pkg.Name.Meta.LineEnd != 2 {
+		t.Errorf("Property line range changed: %#v", pkg.Name.Meta)
+	}
+	file := parser.index["file"].ptr.(*spdx.File)
+	if file.Meta.LineStart != 3 || file.Meta.LineEnd != 5 {
+		t.Errorf("Wrong file line range: %#v", file.Meta)
+	}
+}
+
+func TestLicenceListVersion(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	tests := map[string]bool{"2.6": true, "": true, "2": false, "abc": false, "2.6.1": false}
+	for version, valid := range tests {
+		ci := new(spdx.CreationInfo)
+		err := parser.creationInfoMap(ci).apply(prefix("licenseListVersion"), literal(version), spdx.NewMetaL(4))
+		if valid && (err != nil || ci.LicenceListVersion.Val != version) {
+			t.Errorf("Version %#v rejected: %v", version, err)
+		}
+		if valid {
+			continue
+		}
+		if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrInvalidValue || perr.LineStart != 4 {
+			t.Errorf("Wrong error for version %#v: %#v", version, err)
+		}
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	parser := &Parser{
+		index:      make(map[string]*builder),
+		buffer:     make(map[string][]bufferEntry),
+		Strict:     true,
+		headerOnly: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("specVersion"), Object: literal("SPDX-1.2")},
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("pkg")},
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("creationInfo"), Object: blank("ci")},
+		{Subject: blank("doc"), Predicate: prefix("referencesFile"), Object: blank("file")},
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("pkg")},
+		{Subject: blank("file"), Predicate: prefix("fileName"), Object: literal("f")},
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("ci"), Predicate: prefix("created"), Object: literal("2014-08-26T10:30:00Z")},
+		{Subject: blank("ci"), Predicate: prefix("ns:type"), Object: typeCreationInfo},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+
+	doc := parser.doc
+	if doc == nil || doc.SpecVersion.Val != "SPDX-1.2" {
+		t.Fatalf("Wrong document: %#v", doc)
+	}
+	if doc.CreationInfo == nil || doc.CreationInfo.Created.V() != "2014-08-26T10:30:00Z" {
+		t.Errorf("Wrong creation info: %#v", doc.CreationInfo)
+	}
+	if len(doc.Packages) != 0 || len(doc.Files) != 0 {
+		t.Errorf("Packages or files parsed: %#v, %#v", doc.Packages, doc.Files)
+	}
+	if len(parser.index) != 2 || len(parser.buffer) != 0 {
+		t.Errorf("Wrong parser state: %d nodes, %d buffered.", len(parser.index), len(parser.buffer))
+	}
+}
+
+func TestOnType(t *testing.T) {
+	var found []string
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+		OnType: func(node string, t goraptor.Term) {
+			found = append(found, node+" "+shortPrefix(t))
+		},
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("pkg")},
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("set"), Predicate: prefix("ns:type"), Object: typeConjunctiveSet},
+		{Subject: blank("pkg"), Predicate: prefix("licenseDeclared"), Object: blank("set")},
+		{Subject: blank("set"), Predicate: prefix("member"), Object: uri(licenceUri + "MIT")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	expected := []string{
+		"doc SpdxDocument",
+		"pkg Package",
+		"set ConjunctiveLicenseSet",
+		licenceUri + "MIT AnyLicenseInfo",
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Found types %v (expected %v)", found, expected)
+	}
+}
+
+func TestSourceInfo(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	tests := map[goraptor.Term]string{
+		literal("Built from\n  the git repository\r\nat tag v1.0.\n"): "Built from\n  the git repository\r\nat tag v1.0.\n",
+		prefix("noassertion"): spdx.NOASSERTION,
+		prefix("none"):        spdx.NONE,
+	}
+	for obj, expected := range tests {
+		pkg := new(spdx.Package)
+		if err := parser.packageMap(pkg).apply(prefix("sourceInfo"), obj, nil); err != nil {
+			t.Errorf("Unexpected error for %s: %s", obj, err)
+		}
+		if pkg.SourceInfo.Val != expected {
+			t.Errorf("Wrong source info for %s: %#v (expected %#v)", obj, pkg.SourceInfo.Val, expected)
+		}
+	}
+}
+
+func TestSeeAlso(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("rdfs:seeAlso"), Object: uri("http://example.org/pkg")},
+		{Subject: blank("pkg"), Predicate: prefix("rdfs:seeAlso"), Object: uri("http://example.org/pkg/docs")},
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file"), Predicate: prefix("rdfs:seeAlso"), Object: uri("http://example.org/file")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	if len(pkg.SeeAlso) != 2 || pkg.SeeAlso[0].Val != "http://example.org/pkg" || pkg.SeeAlso[1].Val != "http://example.org/pkg/docs" {
+		t.Errorf("Wrong package seeAlso: %#v", pkg.SeeAlso)
+	}
+	file := parser.index["file"].ptr.(*spdx.File)
+	if len(file.SeeAlso) != 1 || file.SeeAlso[0].Val != "http://example.org/file" || file.SeeAlso[0].Meta.LineStart != 5 {
+		t.Errorf("Wrong file seeAlso: %#v", file.SeeAlso)
+	}
+}
+
+func TestAbstractSets(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("set1"), Predicate: prefix("ns:type"), Object: typeAbstractLicenceSet},
+		{Subject: blank("set1"), Predicate: prefix("member"), Object: uri(licenceUri + "MIT")},
+		{Subject: blank("set2"), Predicate: prefix("ns:type"), Object: typeAbstractLicenceSet},
+		{Subject: blank("set2"), Predicate: prefix("ns:type"), Object: typeDisjunctiveSet},
+		{Subject: blank("set0"), Predicate: prefix("ns:type"), Object: typeAbstractLicenceSet},
+	}
+	for _, strict := range []bool{true, false} {
+		parser := &Parser{
+			index:  make(map[string]*builder),
+			buffer: make(map[string][]bufferEntry),
+			Strict: strict,
+		}
+		for i, stm := range stms {
+			if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+				t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+			}
+		}
+		err := parser.checkAbstractSets()
+		if strict {
+			if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrUnknownType || perr.LineStart != 5 {
+				t.Errorf("Wrong error in Strict mode: %#v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error in lenient mode: %s", err)
+		}
+		if len(parser.warnings) != 2 || parser.warnings[0].LineStart != 5 || parser.warnings[1].LineStart != 1 {
+			t.Errorf("Wrong warnings: %v", parser.warnings)
+		}
+	}
+}
+
+func TestBlankNodePackages(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: uri("http://example.org/doc#SPDXRef-DOCUMENT"), Predicate: prefix("ns:type"), Object: typeDocument},
+		// referenced before being typed
+		{Subject: uri("http://example.org/doc#SPDXRef-DOCUMENT"), Predicate: prefix("describesPackage"), Object: blank("pkg1")},
+		{Subject: blank("pkg1"), Predicate: prefix("hasFile"), Object: blank("file")},
+		{Subject: blank("pkg1"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg1"), Predicate: prefix("name"), Object: literal("first")},
+		// typed before being referenced
+		{Subject: blank("pkg2"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg2"), Predicate: prefix("name"), Object: literal("second")},
+		{Subject: uri("http://example.org/doc#SPDXRef-DOCUMENT"), Predicate: prefix("describesPackage"), Object: blank("pkg2")},
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+
+	doc := parser.doc
+	if len(doc.Packages) != 2 || doc.Packages[0].Name.Val != "first" || doc.Packages[1].Name.Val != "second" {
+		t.Fatalf("Wrong packages: %#v", doc.Packages)
+	}
+	file := parser.index["file"].ptr.(*spdx.File)
+	if len(doc.Packages[0].Files) != 1 || doc.Packages[0].Files[0] != file {
+		t.Errorf("Wrong package files: %#v", doc.Packages[0].Files)
+	}
+	if doc.Packages[0].Meta == nil || doc.Packages[0].Meta.LineStart != 4 {
+		t.Errorf("Wrong package meta: %#v", doc.Packages[0].Meta)
+	}
+	if len(parser.ids) != 0 {
+		t.Errorf("Blank nodes used as SPDX identifiers: %v", parser.ids)
+	}
+}
+
+func TestChecksumValue(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	algo := func(name string) goraptor.Term { return uri("http://spdx.org/rdf/terms#checksumAlgorithm_" + name) }
+
+	cksum := new(spdx.Checksum)
+	bldr := parser.checksumMap(cksum)
+	if err := bldr.apply(prefix("checksumValue"), literal(" 2FD4E1C67A2D28FCED849EE1BB76E7391B93EB12\n"), nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := bldr.apply(prefix("algorithm"), algo("sha1"), nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if cksum.Value.Val != "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12" {
+		t.Errorf("Wrong checksum value %#v", cksum.Value.Val)
+	}
+
+	tests := map[string]string{
+		"sha256": "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12",
+		"md5":    "2fd4e1c67a2d28fced849ee1bb76e7",
+		"sha1":   "not hex",
+	}
+	for name, value := range tests {
+		// the error is found by the second statement, whatever the order
+		bldr := parser.checksumMap(new(spdx.Checksum))
+		if err := bldr.apply(prefix("algorithm"), algo(name), nil); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		err := bldr.apply(prefix("checksumValue"), literal(value), spdx.NewMetaL(8))
+		if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrInvalidValue || perr.LineStart != 8 {
+			t.Errorf("Wrong error for %s checksum %s: %#v", name, value, err)
+		}
+
+		if name == "sha1" {
+			// not hexadecimal, rejected whatever the algorithm
+			continue
+		}
+		bldr = parser.checksumMap(new(spdx.Checksum))
+		if err := bldr.apply(prefix("checksumValue"), literal(value), nil); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := bldr.apply(prefix("algorithm"), algo(name), nil); err == nil {
+			t.Errorf("No error for %s checksum %s.", name, value)
+		}
+	}
+}
+
+func TestSortBySource(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg2"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("file2"), Predicate: prefix("fileName"), Object: literal("2")},
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("pkg1")},
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("pkg2")},
+		{Subject: blank("pkg1"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg1"), Predicate: prefix("hasFile"), Object: blank("file1")},
+		{Subject: blank("pkg1"), Predicate: prefix("hasFile"), Object: blank("file2")},
+		{Subject: blank("file1"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file2"), Predicate: prefix("ns:type"), Object: typeFile},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	doc := parser.doc
+	other := &spdx.Package{}
+	doc.Packages = append(doc.Packages, other)
+	parser.SortBySource(doc)
+
+	pkg1 := parser.index["pkg1"].ptr.(*spdx.Package)
+	pkg2 := parser.index["pkg2"].ptr.(*spdx.Package)
+	if len(doc.Packages) != 3 || doc.Packages[0] != pkg2 || doc.Packages[1] != pkg1 || doc.Packages[2] != other {
+		t.Errorf("Wrong package order: %#v", doc.Packages)
+	}
+	file1 := parser.index["file1"].ptr.(*spdx.File)
+	file2 := parser.index["file2"].ptr.(*spdx.File)
+	if len(pkg1.Files) != 2 || pkg1.Files[0] != file2 || pkg1.Files[1] != file1 {
+		t.Errorf("Wrong file order: %#v", pkg1.Files)
+	}
+}
+
+func TestBlankExtractedLicence(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("genid1"), Predicate: prefix("licenseId"), Object: literal("LicenseRef-1")},
+		{Subject: blank("genid1"), Predicate: prefix("extractedText"), Object: literal("Some text")},
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("licenseConcluded"), Object: blank("genid1")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	lic, ok := pkg.LicenceConcluded.(*spdx.ExtractedLicence)
+	if !ok || lic.Id.Val != "LicenseRef-1" || lic.Text.Val != "Some text" || lic.NodeId != "genid1" {
+		t.Errorf("Wrong licence: %#v", pkg.LicenceConcluded)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	var buf bytes.Buffer
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+		Trace:  &buf,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("unknownProperty"), Object: literal("value")},
+	}
+	if err := parser.processTruple(stms[0], spdx.NewMetaL(1)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := parser.processTruple(stms[1], nil); err == nil {
+		t.Fatal("No error for an unsupported property.")
+	}
+	expected := stms[0].Subject.N3() + " " + stms[0].Predicate.N3() + " " + stms[0].Object.N3() + " . # line 1\n" +
+		stms[1].Subject.N3() + " " + stms[1].Predicate.N3() + " " + stms[1].Object.N3() + " .\n"
+	if buf.String() != expected {
+		t.Errorf("Wrong trace:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestExtractedTextWhitespace(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	text := "\n  Copyright (c) <year> <owner>\t\r\n\n   Permission is hereby granted...  \n\n"
+	lic := new(spdx.ExtractedLicence)
+	if err := parser.extractedLicensingInfoMap(lic).apply(prefix("extractedText"), literal(text), nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if lic.Text.Val != text {
+		t.Errorf("Extracted text changed: %#v (expected %#v)", lic.Text.Val, text)
+	}
+}
+
+func TestUnboundPrefix(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: uri("spdx:Package")},
+		{Subject: blank("pkg"), Predicate: uri("spdx:name"), Object: literal("pkg")},
+		{Subject: blank("pkg"), Predicate: uri("doap:homepage"), Object: literal("http://example.org")},
+		{Subject: blank("pkg"), Predicate: uri("spdx:versionInfo"), Object: literal("1.0")},
+	}
+
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	err := parser.processTruple(stms[0], spdx.NewMetaL(1))
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrUnboundPrefix || perr.LineStart != 1 ||
+		!strings.Contains(perr.Error(), "prefix spdx ") {
+		t.Errorf("Wrong error for an unbound prefix: %#v", err)
+	}
+
+	parser = &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	if len(parser.warnings) != 2 || parser.warnings[0].LineStart != 1 || parser.warnings[1].LineStart != 3 {
+		t.Errorf("Wrong warnings: %v", parser.warnings)
+	}
+	if len(parser.index) != 0 || len(parser.buffer) != 0 {
+		t.Error("Statements with unbound prefixes were processed.")
+	}
+}
+
+func TestFileType(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	file := new(spdx.File)
+	bldr := parser.fileMap(file)
+	for i, typ := range []string{"fileType_source", "SOURCE", "fileType_documentation"} {
+		if err := bldr.apply(prefix("fileType"), prefix(typ), spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error for %s: %s", typ, err)
+		}
+	}
+	if file.Type.Val != "SOURCE" || len(file.ExtraTypes) != 2 || file.ExtraTypes[0].Val != "SOURCE" ||
+		file.ExtraTypes[1].Val != "DOCUMENTATION" || file.ExtraTypes[1].Meta.LineStart != 3 {
+		t.Errorf("Wrong file types: %#v, %#v", file.Type, file.ExtraTypes)
+	}
+
+	err := parser.fileMap(new(spdx.File)).apply(prefix("fileType"), prefix("fileType_sauce"), spdx.NewMetaL(4))
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrInvalidValue || perr.LineStart != 4 {
+		t.Errorf("Wrong error for an unknown file type: %#v", err)
+	}
+}
+
+func TestFileOrigins(t *testing.T) {
+	project := uri("http://example.org/project")
+	upstream := uri("http://example.org/doc#SPDXRef-upstream")
+	stms := []*goraptor.Statement{
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file"), Predicate: prefix("artifactOf"), Object: project},
+		{Subject: project, Predicate: prefix("ns:type"), Object: typeArtifactOf},
+		{Subject: project, Predicate: prefix("doap:name"), Object: literal("project")},
+		{Subject: blank("file"), Predicate: prefix("relationship"), Object: blank("rel")},
+		{Subject: blank("rel"), Predicate: prefix("relationshipType"), Object: prefix("relationshipType_generatedFrom")},
+		{Subject: blank("rel"), Predicate: prefix("relatedSpdxElement"), Object: upstream},
+		{Subject: upstream, Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: upstream, Predicate: prefix("name"), Object: literal("upstream")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	parser.resolveRelated()
+
+	file := parser.index["file"].ptr.(*spdx.File)
+	origins := file.Origins()
+	if len(origins) != 2 {
+		t.Fatalf("Wrong origins: %#v", origins)
+	}
+	if origins[0].Name.Val != "project" {
+		t.Errorf("Wrong artifactOf origin: %#v", origins[0])
+	}
+	if origins[1].ProjectUri.Val != termStr(upstream) || origins[1].Name.Val != "upstream" {
+		t.Errorf("Wrong relationship origin: %#v", origins[1])
+	}
+}
+
+func TestDocumentNamespace(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("documentNamespace"), Object: literal("http://example.org/spdx/doc-1")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	ns := parser.doc.Namespace
+	if ns.Val != "http://example.org/spdx/doc-1" || ns.Meta == nil || ns.Meta.LineStart != 2 {
+		t.Errorf("Wrong document namespace: %#v", ns)
+	}
+}
+
+func TestRegisterType(t *testing.T) {
+	const vendorType = "http://example.org/vendor#Tool"
+	type tool struct{ name spdx.ValueStr }
+
+	stms := []*goraptor.Statement{
+		{Subject: blank("tool"), Predicate: prefix("ns:type"), Object: uri(vendorType)},
+		{Subject: blank("tool"), Predicate: uri("http://example.org/vendor#name"), Object: literal("scanner")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	if err := parser.processTruple(stms[0], spdx.NewMetaL(1)); err == nil {
+		t.Fatal("No error for an unregistered type.")
+	}
+
+	parser.index = make(map[string]*builder)
+	parser.RegisterType(vendorType, func(meta *spdx.Meta) (interface{}, map[string]Updater) {
+		tl := new(tool)
+		return tl, map[string]Updater{
+			"http://example.org/vendor#name": func(obj goraptor.Term, meta *spdx.Meta) error {
+				tl.name = spdx.Str(termStr(obj), meta)
+				return nil
+			},
+		}
+	})
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	bldr := parser.index["tool"]
+	if !bldr.t.Equals(uri(vendorType)) {
+		t.Errorf("Wrong builder type: %s", bldr.t)
+	}
+	if tl, ok := bldr.ptr.(*tool); !ok || tl.name.Val != "scanner" {
+		t.Errorf("Wrong element: %#v", bldr.ptr)
+	}
+}
+
+func TestLicenceSetMemberOrderings(t *testing.T) {
+	// URI licence sets must be typed before being referenced, otherwise they
+	// are licences of the SPDX licence list
+	for _, set := range []goraptor.Term{blank("set"), uri("http://example.org/doc#set")} {
+		outer := blank("outer")
+		typeSet := &goraptor.Statement{Subject: set, Predicate: prefix("ns:type"), Object: typeConjunctiveSet}
+		stms := []*goraptor.Statement{
+			{Subject: set, Predicate: prefix("member"), Object: uri(licenceUri + "MIT")},
+			{Subject: set, Predicate: prefix("member"), Object: uri(licenceUri + "ISC")},
+			{Subject: set, Predicate: prefix("member"), Object: uri(licenceUri + "Zlib")},
+			{Subject: outer, Predicate: prefix("member"), Object: set},
+			{Subject: blank("pkg"), Predicate: prefix("licenseConcluded"), Object: set},
+		}
+		header := []*goraptor.Statement{
+			{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+			{Subject: blank("pkg"), Predicate: prefix("licenseDeclared"), Object: outer},
+			{Subject: outer, Predicate: prefix("ns:type"), Object: typeDisjunctiveSet},
+		}
+		if _, ok := set.(*goraptor.Uri); ok {
+			header = append(header, typeSet)
+		} else {
+			stms = append(stms, typeSet)
+		}
+
+		check := func(order []*goraptor.Statement) {
+			parser := &Parser{
+				index:  make(map[string]*builder),
+				buffer: make(map[string][]bufferEntry),
+				Strict: true,
+			}
+			for i, stm := range append(header, order...) {
+				if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+					t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+				}
+			}
+			parser.resolveLicenceSets()
+
+			pkg := parser.index["pkg"].ptr.(*spdx.Package)
+			expected := spdx.NewConjunctiveSet(nil, spdx.NewLicence("MIT", nil), spdx.NewLicence("ISC", nil), spdx.NewLicence("Zlib", nil))
+			if conj, ok := pkg.LicenceConcluded.(spdx.ConjunctiveLicenceSet); !ok || !spdx.SameLicence(spdx.CanonicalLicence(conj), spdx.CanonicalLicence(expected)) {
+				t.Errorf("Wrong licence concluded for %s %v: %#v", set, order, pkg.LicenceConcluded)
+			}
+			disj, ok := pkg.LicenceDeclared.(spdx.DisjunctiveLicenceSet)
+			if !ok || len(disj.Members) != 1 || !spdx.SameLicence(spdx.CanonicalLicence(disj.Members[0]), spdx.CanonicalLicence(expected)) {
+				t.Errorf("Wrong licence declared for %s %v: %#v", set, order, pkg.LicenceDeclared)
+			}
+		}
+
+		// all the orderings of stms (Heap's algorithm)
+		var permute func(order []*goraptor.Statement, n int)
+		permute = func(order []*goraptor.Statement, n int) {
+			if n == 1 {
+				check(order)
+				return
+			}
+			for i := 0; i < n; i++ {
+				permute(order, n-1)
+				if n%2 == 0 {
+					order[i], order[n-1] = order[n-1], order[i]
+				} else {
+					order[0], order[n-1] = order[n-1], order[0]
+				}
+			}
+		}
+		permute(stms, len(stms))
+	}
+}
+
+func TestRegisterProperty(t *testing.T) {
+	const vendorProperty = "http://example.org/vendor#buildTool"
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	var tools []string
+	parser.RegisterProperty(termStr(typePackage), vendorProperty, func(obj goraptor.Term, meta *spdx.Meta) error {
+		tools = append(tools, termStr(obj))
+		return nil
+	})
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: uri(vendorProperty), Object: literal("make")},
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("pkg")},
+		{Subject: blank("pkg"), Predicate: uri(vendorProperty), Object: literal("go")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	if !reflect.DeepEqual(tools, []string{"make", "go"}) {
+		t.Errorf("Wrong vendor property values: %v", tools)
+	}
+	if pkg := parser.index["pkg"].ptr.(*spdx.Package); pkg.Name.Val != "pkg" {
+		t.Errorf("Built-in property not applied: %#v", pkg.Name)
+	}
+
+	stm := &goraptor.Statement{Subject: blank("file"), Predicate: uri(vendorProperty), Object: literal("make")}
+	parser.processTruple(&goraptor.Statement{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile}, nil)
+	if err := parser.processTruple(stm, nil); err == nil {
+		t.Error("Property registered for packages accepted on a file.")
+	}
+}
+
+func TestRegisterPropertyPromotedSet(t *testing.T) {
+	const vendorProperty = "http://example.org/vendor#origin"
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	var origins []string
+	origin := func(obj goraptor.Term, meta *spdx.Meta) error {
+		origins = append(origins, termStr(obj))
+		return nil
+	}
+	parser.RegisterProperty(termStr(typeConjunctiveSet), vendorProperty, origin)
+	parser.RegisterProperty(termStr(typeWithException), vendorProperty, origin)
+
+	// the sets are referenced as members before being typed
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("licenseConcluded"), Object: blank("outer")},
+		{Subject: blank("outer"), Predicate: prefix("ns:type"), Object: typeDisjunctiveSet},
+		{Subject: blank("outer"), Predicate: prefix("member"), Object: blank("set")},
+		{Subject: blank("outer"), Predicate: prefix("member"), Object: blank("with")},
+		{Subject: blank("set"), Predicate: prefix("ns:type"), Object: typeConjunctiveSet},
+		{Subject: blank("set"), Predicate: uri(vendorProperty), Object: literal("set")},
+		{Subject: blank("with"), Predicate: prefix("ns:type"), Object: typeWithException},
+		{Subject: blank("with"), Predicate: uri(vendorProperty), Object: literal("with")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	if len(origins) != 2 || origins[0] != "set" || origins[1] != "with" {
+		t.Errorf("Wrong registered property values: %v", origins)
+	}
+}
+
+func TestElement(t *testing.T) {
+	const vendorProperty = "http://example.org/vendor#buildTool"
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	var names []string
+	parser.RegisterProperty(termStr(typePackage), vendorProperty, func(obj goraptor.Term, meta *spdx.Meta) error {
+		el, ok := parser.Element("pkg")
+		if !ok {
+			t.Fatal("No element for the node being built.")
+		}
+		names = append(names, el.(*spdx.Package).Name.Val)
+		return nil
+	})
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: uri(vendorProperty), Object: literal("make")},
+		{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("pkg")},
+		{Subject: blank("pkg"), Predicate: uri(vendorProperty), Object: literal("go")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"", "pkg"}) {
+		t.Errorf("Wrong names seen while parsing: %q", names)
+	}
+	if el, ok := parser.Element("pkg"); !ok || el != parser.index["pkg"].ptr {
+		t.Errorf("Wrong element: %#v", el)
+	}
+	if el, ok := parser.Element("missing"); ok || el != nil {
+		t.Errorf("Element found for an unknown node: %#v", el)
+	}
+}
+
+func TestIncompatibleTypesPositions(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	if _, err := parser.setType(blank("node"), typePackage, spdx.NewMetaL(10)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	_, err := parser.setType(blank("node"), typeFile, spdx.NewMetaL(200))
+	perr, ok := err.(*spdx.ParseError)
+	if !ok || perr.Code != spdx.ErrIncompatibleTypes {
+		t.Fatalf("Wrong error: %#v", err)
+	}
+	if perr.LineStart != 200 || perr.Previous == nil || perr.Previous.LineStart != 10 {
+		t.Errorf("Wrong positions: %#v and %#v", perr.Meta, perr.Previous)
+	}
+	if !strings.Contains(perr.Error(), "line 10") {
+		t.Errorf("Error message without the first position: %s", perr)
+	}
+
+	// referenced first, then typed
+	if _, err := parser.reqType(blank("ref"), typeCreationInfo); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, err := parser.setType(blank("ref"), typeCreationInfo, spdx.NewMetaL(20)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	_, err = parser.setType(blank("ref"), typeReview, spdx.NewMetaL(30))
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.Previous == nil || perr.Previous.LineStart != 20 {
+		t.Errorf("Wrong error for a node typed after being referenced: %#v", err)
+	}
+}
+
+func TestExtractedLicenceLabel(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("lic"), Predicate: prefix("ns:type"), Object: typeExtractedLicence},
+		{Subject: blank("lic"), Predicate: prefix("licenseId"), Object: literal("LicenseRef-1")},
+		{Subject: blank("lic"), Predicate: prefix("name"), Object: literal("First name")},
+		{Subject: blank("lic"), Predicate: prefix("rdfs:label"), Object: literal("Label")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	lic := parser.index["lic"].ptr.(*spdx.ExtractedLicence)
+	if len(lic.Name) != 2 || lic.Name[0].Val != "First name" || lic.Name[1].Val != "Label" {
+		t.Errorf("Wrong licence names: %#v", lic.Name)
+	}
+}
+
+func TestMaxBuffered(t *testing.T) {
+	parser := &Parser{
+		index:       make(map[string]*builder),
+		buffer:      make(map[string][]bufferEntry),
+		Strict:      true,
+		MaxBuffered: 2,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("name"), Object: literal("pkg")},
+		{Subject: blank("pkg"), Predicate: prefix("versionInfo"), Object: literal("1.0")},
+		// applies and frees the buffered statements
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("file1"), Predicate: prefix("fileName"), Object: literal("a")},
+		{Subject: blank("file2"), Predicate: prefix("fileName"), Object: literal("b")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	stm := &goraptor.Statement{Subject: blank("file3"), Predicate: prefix("fileName"), Object: literal("c")}
+	err := parser.processTruple(stm, spdx.NewMetaL(6))
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrLimitExceeded || perr.LineStart != 6 {
+		t.Errorf("Wrong error for too many buffered statements: %#v", err)
+	}
+}
+
+func TestExcludedFileReferences(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("vc"), Predicate: prefix("ns:type"), Object: typeVerificationCode},
+		{Subject: blank("vc"), Predicate: prefix("packageVerificationCodeExcludedFile"), Object: literal("./a.spdx")},
+		{Subject: blank("vc"), Predicate: prefix("packageVerificationCodeExcludedFile"), Object: blank("file")},
+		{Subject: blank("vc"), Predicate: prefix("packageVerificationCodeExcludedFile"), Object: literal("./c.spdx")},
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file"), Predicate: prefix("fileName"), Object: literal("./b.spdx")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	parser.resolveExcludedFiles()
+
+	vc := parser.index["vc"].ptr.(*spdx.VerificationCode)
+	expected := []string{"./a.spdx", "./b.spdx", "./c.spdx"}
+	if len(vc.ExcludedFiles) != len(expected) {
+		t.Fatalf("Wrong excluded files: %#v", vc.ExcludedFiles)
+	}
+	for i, name := range expected {
+		if vc.ExcludedFiles[i].Val != name || vc.ExcludedFiles[i].Meta.LineStart != i+2 {
+			t.Errorf("Wrong excluded file %d: %#v", i, vc.ExcludedFiles[i])
+		}
+	}
+}
+
+func TestLicenceSentinels(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("licenseConcluded"), Object: prefix("noassertion")},
+		{Subject: blank("pkg"), Predicate: prefix("licenseDeclared"), Object: prefix("none")},
+		{Subject: blank("pkg"), Predicate: prefix("licenseInfoFromFiles"), Object: uri(licenceUri + "NOASSERTION")},
+		{Subject: blank("pkg"), Predicate: prefix("licenseInfoFromFiles"), Object: uri(licenceUri + "MIT")},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	if _, ok := pkg.LicenceConcluded.(spdx.NoAssertionLicence); !ok {
+		t.Errorf("Wrong licence concluded: %#v", pkg.LicenceConcluded)
+	}
+	if _, ok := pkg.LicenceDeclared.(spdx.NoneLicence); !ok {
+		t.Errorf("Wrong licence declared: %#v", pkg.LicenceDeclared)
+	}
+	if len(pkg.LicenceInfoFromFiles) != 2 {
+		t.Fatalf("Wrong licence info from files: %#v", pkg.LicenceInfoFromFiles)
+	}
+	if _, ok := pkg.LicenceInfoFromFiles[0].(spdx.NoAssertionLicence); !ok {
+		t.Errorf("Wrong licence info from files: %#v", pkg.LicenceInfoFromFiles[0])
+	}
+	if lic, ok := pkg.LicenceInfoFromFiles[1].(*spdx.Licence); !ok || lic.V() != "MIT" {
+		t.Errorf("Wrong licence info from files: %#v", pkg.LicenceInfoFromFiles[1])
+	}
+}
+
+func TestPrimaryPackagePurpose(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("primaryPackagePurpose"), Object: prefix("purpose_operatingSystem")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	if pkg.PrimaryPurpose.Val != "OPERATING-SYSTEM" || pkg.PrimaryPurpose.Meta.LineStart != 2 {
+		t.Errorf("Wrong primary purpose: %#v", pkg.PrimaryPurpose)
+	}
+	if err := parser.processTruple(stms[1], nil); err == nil {
+		t.Error("No error for a second primary purpose.")
+	}
+
+	invalid := &goraptor.Statement{Subject: blank("pkg2"), Predicate: prefix("primaryPackagePurpose"), Object: prefix("purpose_toaster")}
+	if err := parser.processTruple(&goraptor.Statement{Subject: blank("pkg2"), Predicate: prefix("ns:type"), Object: typePackage}, nil); err != nil {
+		t.Fatal(err)
+	}
+	err := parser.processTruple(invalid, nil)
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrInvalidValue {
+		t.Errorf("Wrong error for an unknown primary purpose: %v", err)
+	}
+}
+
+func TestKeepUnknownTypes(t *testing.T) {
+	vuln := prefix("Vulnerability")
+	stms := []*goraptor.Statement{
+		{Subject: blank("v"), Predicate: prefix("summary"), Object: literal("Buffer overflow")},
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("v"), Predicate: prefix("ns:type"), Object: vuln},
+		{Subject: blank("v"), Predicate: prefix("rdfs:seeAlso"), Object: uri("http://example.org/a")},
+		{Subject: blank("v"), Predicate: prefix("rdfs:seeAlso"), Object: uri("http://example.org/b")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	if err := parser.processTruple(stms[2], nil); err == nil {
+		t.Error("No error for an unknown type.")
+	}
+
+	parser = &Parser{
+		index:            make(map[string]*builder),
+		buffer:           make(map[string][]bufferEntry),
+		Strict:           true,
+		KeepUnknownTypes: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	el := parser.index["v"].ptr.(*spdx.UnknownElement)
+	expected := &spdx.UnknownElement{
+		Type: spdx.Str(termStr(vuln), nil),
+		Properties: map[string][]spdx.ValueStr{
+			termStr(prefix("summary")):      {spdx.Str("Buffer overflow", nil)},
+			termStr(prefix("rdfs:seeAlso")): {spdx.Str("http://example.org/a", nil), spdx.Str("http://example.org/b", nil)},
+		},
+	}
+	if !el.Equal(expected) || el.Node != "v" {
+		t.Errorf("Wrong unknown element: %#v", el)
+	}
+	if len(parser.unknownElements) != 1 || parser.unknownElements[0] != el {
+		t.Errorf("Wrong unknown elements: %#v", parser.unknownElements)
+	}
+}
+
+func TestPackageComment(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("rdfs:comment"), Object: literal("Built from the 1.2 tag.")},
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	if pkg.Comment.Val != "Built from the 1.2 tag." || pkg.Comment.Meta == nil || pkg.Comment.Meta.LineStart != 1 {
+		t.Errorf("Wrong package comment: %#v", pkg.Comment)
+	}
+}
+
+func TestPackageDates(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("releaseDate"), Object: literal("2021-09-01T00:00:00Z")},
+		{Subject: blank("pkg"), Predicate: prefix("builtDate"), Object: literal("2021-08-30T12:00:00Z")},
+		{Subject: blank("pkg"), Predicate: prefix("validUntilDate"), Object: literal("2026-09-01T00:00:00Z")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	dates := map[string]spdx.ValueDate{
+		"2021-09-01T00:00:00Z": pkg.ReleaseDate,
+		"2021-08-30T12:00:00Z": pkg.BuiltDate,
+		"2026-09-01T00:00:00Z": pkg.ValidUntilDate,
+	}
+	for expected, date := range dates {
+		if date.V() != expected || date.Time() == nil {
+			t.Errorf("Found date %#v, expected %s.", date, expected)
+		}
+	}
+
+	invalid := &goraptor.Statement{Subject: blank("pkg2"), Predicate: prefix("builtDate"), Object: literal("2021-08-30")}
+	if err := parser.processTruple(&goraptor.Statement{Subject: blank("pkg2"), Predicate: prefix("ns:type"), Object: typePackage}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.processTruple(invalid, nil); err == nil {
+		t.Error("No error for a date not in the SPDX format.")
+	}
+}
+
+func TestAttributionText(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("copyrightText"), Object: literal("Copyright 2020 Example")},
+		{Subject: blank("pkg"), Predicate: prefix("attributionText"), Object: literal("Includes software by Example.")},
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file"), Predicate: prefix("copyrightText"), Object: literal("Copyright 2019 Other")},
+		{Subject: blank("file"), Predicate: prefix("noticeText"), Object: literal("NOTICE")},
+		{Subject: blank("file"), Predicate: prefix("attributionText"), Object: literal("First attribution.")},
+		{Subject: blank("file"), Predicate: prefix("attributionText"), Object: literal("Second attribution.")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	if pkg.CopyrightText.Val != "Copyright 2020 Example" {
+		t.Errorf("Wrong package copyright text: %#v", pkg.CopyrightText)
+	}
+	if len(pkg.AttributionText) != 1 || pkg.AttributionText[0].Val != "Includes software by Example." {
+		t.Errorf("Wrong package attribution text: %#v", pkg.AttributionText)
+	}
+
+	file := parser.index["file"].ptr.(*spdx.File)
+	if file.CopyrightText.Val != "Copyright 2019 Other" || file.Notice.Val != "NOTICE" {
+		t.Errorf("Wrong file copyright or notice text: %#v, %#v", file.CopyrightText, file.Notice)
+	}
+	if len(file.AttributionText) != 2 || file.AttributionText[0].Val != "First attribution." || file.AttributionText[1].Val != "Second attribution." {
+		t.Errorf("Wrong file attribution text: %#v", file.AttributionText)
+	}
+}
+
+func TestDescribesPackageTwice(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("pkg")},
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("other")},
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("pkg")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	doc := parser.index["doc"].ptr.(*spdx.Document)
+	if len(doc.Packages) != 2 || doc.Packages[0] != parser.index["pkg"].ptr || doc.Packages[1] != parser.index["other"].ptr {
+		t.Errorf("Wrong packages: %#v", doc.Packages)
+	}
+}
+
+func TestCaseInsensitiveProperties(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("VersionInfo"), Object: literal("1.0")},
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file"), Predicate: prefix("rdfs:Comment"), Object: literal("comment")},
+		{Subject: blank("file"), Predicate: prefix("versionInfo"), Object: literal("1.0")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	if err := parser.processTruple(stms[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.processTruple(stms[1], nil); err == nil {
+		t.Error("No error for a property in the wrong case.")
+	}
+
+	parser.CaseInsensitiveProperties = true
+	for i, stm := range stms[1:4] {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+2)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	if pkg.Version.Val != "1.0" || pkg.Version.Meta.LineStart != 2 {
+		t.Errorf("Wrong version: %#v", pkg.Version)
+	}
+	file := parser.index["file"].ptr.(*spdx.File)
+	if file.Comment.Val != "comment" {
+		t.Errorf("Wrong comment: %#v", file.Comment)
+	}
+
+	// Files have no versionInfo property in any case.
+	if err := parser.processTruple(stms[4], nil); err == nil {
+		t.Error("No error for an unsupported property.")
+	}
+}
+
+func TestDeprecatedProperties(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file"), Predicate: prefix("fileName"), Object: literal("foo.c")},
+		{Subject: blank("file"), Predicate: prefix("artifactOf"), Object: blank("project")},
+		{Subject: blank("project"), Predicate: prefix("ns:type"), Object: typeArtifactOf},
+		{Subject: blank("project"), Predicate: prefix("doap:name"), Object: literal("Project")},
+		{Subject: blank("project"), Predicate: prefix("doap:homepage"), Object: literal("http://example.org")},
+	}
+	for _, strict := range []bool{true, false} {
+		parser := &Parser{
+			index:  make(map[string]*builder),
+			buffer: make(map[string][]bufferEntry),
+			Strict: strict,
+		}
+		for i, stm := range stms {
+			if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+				t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+			}
+		}
+		file := parser.index["file"].ptr.(*spdx.File)
+		if len(file.ArtifactOf) != 1 || file.ArtifactOf[0].Name.Val != "Project" {
+			t.Errorf("Wrong artifacts: %#v", file.ArtifactOf)
+		}
+		warns := parser.Warnings()
+		lines := []int{3, 5, 6}
+		if len(warns) != len(lines) {
+			t.Fatalf("Found warnings %v (strict: %t)", warns, strict)
+		}
+		for i, w := range warns {
+			if w.Code != spdx.ErrDeprecated || w.Meta == nil || w.Meta.LineStart != lines[i] {
+				t.Errorf("Wrong warning %d: %#v", i, w)
+			}
+		}
+	}
+}
+
+func TestSkipFiles(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("pkg")},
+		{Subject: blank("doc"), Predicate: prefix("referencesFile"), Object: blank("file1")},
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("pkg"), Predicate: prefix("hasFile"), Object: blank("file2")},
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("licenseDeclared"), Object: uri(licenceUri + "MIT")},
+		{Subject: blank("pkg"), Predicate: prefix("checksum"), Object: blank("cksum")},
+		{Subject: blank("cksum"), Predicate: prefix("ns:type"), Object: typeChecksum},
+		{Subject: blank("cksum"), Predicate: prefix("algorithm"), Object: prefix("checksumAlgorithm_sha1")},
+		{Subject: blank("cksum"), Predicate: prefix("checksumValue"), Object: literal("d6a770ba38583ed4bb4525bd96e50461655d2758")},
+		{Subject: blank("file1"), Predicate: prefix("fileName"), Object: literal("foo.c")},
+		{Subject: blank("file1"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file2"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file2"), Predicate: prefix("fileName"), Object: literal("bar.c")},
+		{Subject: blank("file3"), Predicate: prefix("fileName"), Object: literal("baz.c")},
+		{Subject: blank("file3"), Predicate: prefix("ns:type"), Object: typeFile},
+	}
+	parser := &Parser{
+		index:     make(map[string]*builder),
+		buffer:    make(map[string][]bufferEntry),
+		Strict:    true,
+		SkipFiles: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	doc := parser.index["doc"].ptr.(*spdx.Document)
+	if len(doc.Files) != 0 || len(doc.Packages) != 1 {
+		t.Fatalf("Wrong document files or packages: %#v, %#v", doc.Files, doc.Packages)
+	}
+	pkg := doc.Packages[0]
+	if len(pkg.Files) != 0 {
+		t.Errorf("Files found in the package: %#v", pkg.Files)
+	}
+	if pkg.LicenceDeclared == nil || pkg.LicenceDeclared.LicenceId() != "MIT" {
+		t.Errorf("Wrong declared licence: %#v", pkg.LicenceDeclared)
+	}
+	if pkg.Checksum == nil || pkg.Checksum.Value.Val != "d6a770ba38583ed4bb4525bd96e50461655d2758" {
+		t.Errorf("Wrong checksum: %#v", pkg.Checksum)
+	}
+	for _, node := range []string{"file1", "file2", "file3"} {
+		if _, ok := parser.index[node]; ok {
+			t.Errorf("File %s built.", node)
+		}
+	}
+	if len(parser.buffer) != 0 || parser.buffered != 0 {
+		t.Errorf("Statements left in the buffer: %#v", parser.buffer)
+	}
+}
+
+func TestStats(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("pkg")},
+		{Subject: blank("doc"), Predicate: prefix("reviewed"), Object: blank("rev")},
+		{Subject: blank("pkg"), Predicate: prefix("hasFile"), Object: blank("file")},
+		{Subject: blank("pkg"), Predicate: prefix("licenseDeclared"), Object: blank("set")},
+		{Subject: blank("set"), Predicate: prefix("ns:type"), Object: typeDisjunctiveSet},
+		{Subject: blank("set"), Predicate: prefix("member"), Object: uri(licenceUri + "MIT")},
+		{Subject: blank("set"), Predicate: prefix("member"), Object: uri(licenceUri + "GPL-2.0")},
+		// neither the exception, first built as a set, nor NOASSERTION are licences
+		{Subject: blank("set"), Predicate: prefix("member"), Object: blank("with")},
+		{Subject: blank("with"), Predicate: prefix("ns:type"), Object: typeWithException},
+		{Subject: blank("pkg"), Predicate: prefix("licenseConcluded"), Object: uri(licenceUri + "NOASSERTION")},
+		{Subject: blank("file"), Predicate: prefix("checksum"), Object: blank("cksum")},
+		{Subject: blank("cksum"), Predicate: prefix("ns:type"), Object: typeChecksum},
+		{Subject: blank("orphan"), Predicate: prefix("name"), Object: literal("orphan")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	expected := ParseStats{
+		Statements: len(stms),
+		Packages:   1,
+		Files:      1,
+		Licences:   3,
+		Checksums:  1,
+		Reviews:    1,
+		Unresolved: 1,
+	}
+	if stats := parser.Stats(); stats != expected {
+		t.Errorf("Found %+v (expected %+v)", stats, expected)
+	}
+}
+
+func TestNormalizeFileNames(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("file1"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file1"), Predicate: prefix("fileName"), Object: literal("src\\a.c")},
+		{Subject: blank("file2"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file2"), Predicate: prefix("fileName"), Object: literal("./src/b.c")},
+	}
+	parser := &Parser{
+		index:              make(map[string]*builder),
+		buffer:             make(map[string][]bufferEntry),
+		Strict:             true,
+		NormalizeFileNames: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	file1 := parser.index["file1"].ptr.(*spdx.File)
+	if file1.Name.Val != "./src/a.c" || file1.Name.Meta.LineStart != 2 {
+		t.Errorf("Wrong name: %#v", file1.Name)
+	}
+	if file1.OriginalName.Val != "src\\a.c" {
+		t.Errorf("Wrong original name: %#v", file1.OriginalName)
+	}
+	file2 := parser.index["file2"].ptr.(*spdx.File)
+	if file2.Name.Val != "./src/b.c" || file2.OriginalName.Val != "" {
+		t.Errorf("Wrong name or original name: %#v, %#v", file2.Name, file2.OriginalName)
+	}
+	if err := parser.processTruple(stms[1], nil); err == nil {
+		t.Error("No error for a second file name.")
+	}
+}
+
+func TestUndefinedFiles(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("referencesFile"), Object: blank("file1")},
+		{Subject: blank("doc"), Predicate: prefix("describesPackage"), Object: blank("pkg")},
+		{Subject: blank("pkg"), Predicate: prefix("hasFile"), Object: blank("file2")},
+		{Subject: blank("file1"), Predicate: prefix("fileDependency"), Object: blank("file3")},
+		{Subject: blank("file2"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file2"), Predicate: prefix("fileName"), Object: literal("foo.c")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	parser.checkFileRefs()
+
+	// file1 and file3 are never typed; file2 is typed after its reference
+	warns := parser.Warnings()
+	lines := []int{2, 5}
+	if len(warns) != len(lines) {
+		t.Fatalf("Found warnings %v", warns)
+	}
+	for i, w := range warns {
+		if w.Code != spdx.ErrUndefinedElement || w.Meta == nil || w.Meta.LineStart != lines[i] {
+			t.Errorf("Wrong warning %d: %#v", i, w)
+		}
+	}
+}
+
+func TestAddNamespace(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: uri("http://www.w3.org/1999/02/22-rdf-syntax-ns#type"), Object: uri("http://example.org/spdx#Package")},
+		{Subject: blank("pkg"), Predicate: uri("http://example.org/spdx#name"), Object: literal("pkg")},
+		{Subject: blank("pkg"), Predicate: uri("s:versionInfo"), Object: literal("1.0")},
+		{Subject: blank("pkg"), Predicate: uri("project:homepage"), Object: literal("http://example.org")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	parser.AddNamespace("spdx", "http://example.org/spdx#")
+	parser.AddNamespace("spdx:", "s:")
+	parser.AddNamespace("doap", "project:")
+	parser.AddNamespace("bogus", "http://example.org/bogus#")
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	if pkg.Name.Val != "pkg" || pkg.Version.Val != "1.0" || pkg.HomePage.Val != "http://example.org" {
+		t.Errorf("Wrong package: %#v", pkg)
+	}
+	if len(parser.namespaces) != 3 {
+		t.Errorf("Wrong namespaces: %#v", parser.namespaces)
+	}
+}
+
+func TestAnnotationType(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg"), Predicate: prefix("annotation"), Object: blank("ann1")},
+		{Subject: blank("ann1"), Predicate: prefix("annotator"), Object: literal("Person: Jane Doe ()")},
+		{Subject: blank("ann1"), Predicate: prefix("annotationDate"), Object: literal("2011-01-29T18:30:22Z")},
+		{Subject: blank("ann1"), Predicate: prefix("annotationType"), Object: prefix("annotationType_review")},
+		{Subject: blank("ann1"), Predicate: prefix("rdfs:comment"), Object: literal("Looks good.")},
+		{Subject: blank("pkg"), Predicate: prefix("annotation"), Object: blank("ann2")},
+		{Subject: blank("ann2"), Predicate: prefix("annotationType"), Object: prefix("annotationType_other")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg := parser.index["pkg"].ptr.(*spdx.Package)
+	expected := []*spdx.Annotation{
+		{
+			Annotator: spdx.NewValueCreator("Person: Jane Doe ()", nil),
+			Date:      spdx.NewValueDate("2011-01-29T18:30:22Z", nil),
+			Type:      spdx.Str("REVIEW", nil),
+			Comment:   spdx.Str("Looks good.", nil),
+		},
+		{Type: spdx.Str("OTHER", nil)},
+	}
+	if len(pkg.Annotations) != len(expected) {
+		t.Fatalf("Wrong annotations: %#v", pkg.Annotations)
+	}
+	for i, ann := range expected {
+		if !pkg.Annotations[i].Equal(ann) {
+			t.Errorf("Wrong annotation %d: %#v", i, pkg.Annotations[i])
+		}
+	}
+
+	stms = []*goraptor.Statement{
+		{Subject: blank("ann3"), Predicate: prefix("ns:type"), Object: typeAnnotation},
+		{Subject: blank("ann3"), Predicate: prefix("annotationType"), Object: prefix("annotationType_approval")},
+	}
+	if err := parser.processTruple(stms[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	err := parser.processTruple(stms[1], spdx.NewMetaL(12))
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrInvalidValue || perr.Meta == nil || perr.LineStart != 12 {
+		t.Errorf("Wrong error for an unknown annotation type: %#v", err)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("doc"), Predicate: prefix("ns:type"), Object: typeDocument},
+		{Subject: blank("doc"), Predicate: prefix("snippet"), Object: uri("#SPDXRef-Snippet")},
+		{Subject: uri("#SPDXRef-Snippet"), Predicate: prefix("snippetFromFile"), Object: blank("file")},
+		{Subject: uri("#SPDXRef-Snippet"), Predicate: prefix("range"), Object: blank("range")},
+		{Subject: blank("range"), Predicate: prefix("ptr:startPointer"), Object: blank("start")},
+		{Subject: blank("start"), Predicate: prefix("ptr:offset"), Object: literal("310")},
+		{Subject: blank("start"), Predicate: prefix("ptr:reference"), Object: blank("file")},
+		{Subject: blank("start"), Predicate: prefix("ns:type"), Object: typeByteOffsetPointer},
+		{Subject: blank("range"), Predicate: prefix("ptr:endPointer"), Object: blank("end")},
+		{Subject: blank("end"), Predicate: prefix("ns:type"), Object: typeByteOffsetPointer},
+		{Subject: blank("end"), Predicate: prefix("ptr:offset"), Object: literal("420")},
+		{Subject: blank("range"), Predicate: prefix("ns:type"), Object: typeStartEndPointer},
+		{Subject: uri("#SPDXRef-Snippet"), Predicate: prefix("licenseConcluded"), Object: uri(licenceUri + "GPL-2.0")},
+		{Subject: uri("#SPDXRef-Snippet"), Predicate: prefix("licenseInfoInSnippet"), Object: uri(licenceUri + "GPL-2.0")},
+		{Subject: uri("#SPDXRef-Snippet"), Predicate: prefix("copyrightText"), Object: literal("Copyright 2008-2010 John Smith")},
+		{Subject: uri("#SPDXRef-Snippet"), Predicate: prefix("ns:type"), Object: typeSnippet},
+		{Subject: blank("file"), Predicate: prefix("ns:type"), Object: typeFile},
+		{Subject: blank("file"), Predicate: prefix("fileName"), Object: literal("./src/main.c")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	doc := parser.index["doc"].ptr.(*spdx.Document)
+	if len(doc.Snippets) != 1 {
+		t.Fatalf("Wrong snippets: %#v", doc.Snippets)
+	}
+	snip := doc.Snippets[0]
+	file := parser.index["file"].ptr.(*spdx.File)
+	expected := &spdx.Snippet{
+		File: file,
+		Ranges: []*spdx.SnippetRange{{
+			Start: &spdx.SnippetPointer{Offset: spdx.Str("310", nil)},
+			End:   &spdx.SnippetPointer{Offset: spdx.Str("420", nil)},
+		}},
+		LicenceConcluded:     spdx.NewLicence("GPL-2.0", nil),
+		LicenceInfoInSnippet: []spdx.AnyLicence{spdx.NewLicence("GPL-2.0", nil)},
+		CopyrightText:        spdx.Str("Copyright 2008-2010 John Smith", nil),
+	}
+	if !snip.Equal(expected) {
+		t.Errorf("Wrong snippet: %#v", snip)
+	}
+	if snip.File != file || snip.Ranges[0].Start.Reference != file || file.Name.Val != "./src/main.c" {
+		t.Errorf("Snippet not linked to its file: %#v", snip)
+	}
+	if bldr := parser.index["start"]; !equalTypes(bldr.t, typeByteOffsetPointer) {
+		t.Errorf("Wrong pointer type: %s", bldr.t)
+	}
+
+	stm := &goraptor.Statement{Subject: blank("end"), Predicate: prefix("ns:type"), Object: typeLineCharPointer}
+	err := parser.processTruple(stm, spdx.NewMetaL(20))
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrIncompatibleTypes {
+		t.Errorf("Wrong error for a pointer with two types: %#v", err)
+	}
+}
+
+func TestAssignLicenseRefs(t *testing.T) {
+	stms := []*goraptor.Statement{
+		{Subject: blank("b"), Predicate: prefix("ns:type"), Object: typeExtractedLicence},
+		{Subject: blank("b"), Predicate: prefix("extractedText"), Object: literal("Text B")},
+		{Subject: blank("c"), Predicate: prefix("ns:type"), Object: typeExtractedLicence},
+		{Subject: blank("c"), Predicate: prefix("licenseId"), Object: literal("LicenseRef-1")},
+		{Subject: blank("a"), Predicate: prefix("ns:type"), Object: typeExtractedLicence},
+		{Subject: blank("a"), Predicate: prefix("extractedText"), Object: literal("Text A")},
+	}
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	parser.assignLicenseRefs()
+	expected := map[string]string{"b": "LicenseRef-2", "c": "LicenseRef-1", "a": "LicenseRef-3"}
+	for node, id := range expected {
+		if lic := parser.index[node].ptr.(*spdx.ExtractedLicence); lic.Id.Val != id {
+			t.Errorf("Wrong identifier for %s: %q instead of %q", node, lic.Id.Val, id)
+		}
+	}
+}
+
+func TestFilesAnalyzed(t *testing.T) {
+	parser := &Parser{
+		index:  make(map[string]*builder),
+		buffer: make(map[string][]bufferEntry),
+		Strict: true,
+	}
+	stms := []*goraptor.Statement{
+		{Subject: blank("pkg1"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg1"), Predicate: prefix("filesAnalyzed"), Object: literal("false")},
+		{Subject: blank("pkg2"), Predicate: prefix("ns:type"), Object: typePackage},
+		{Subject: blank("pkg2"), Predicate: prefix("filesAnalyzed"), Object: literal("true")},
+		{Subject: blank("pkg3"), Predicate: prefix("ns:type"), Object: typePackage},
+	}
+	for i, stm := range stms {
+		if err := parser.processTruple(stm, spdx.NewMetaL(i+1)); err != nil {
+			t.Fatalf("Unexpected error while processing %#v: %s", *stm, err)
+		}
+	}
+	pkg1 := parser.index["pkg1"].ptr.(*spdx.Package)
+	if pkg1.FilesAnalyzed == nil || *pkg1.FilesAnalyzed || pkg1.Analyzed() {
+		t.Errorf("Wrong filesAnalyzed: %v", pkg1.FilesAnalyzed)
+	}
+	if pkg2 := parser.index["pkg2"].ptr.(*spdx.Package); pkg2.FilesAnalyzed == nil || !*pkg2.FilesAnalyzed {
+		t.Errorf("Wrong filesAnalyzed: %v", pkg2.FilesAnalyzed)
+	}
+	if pkg3 := parser.index["pkg3"].ptr.(*spdx.Package); pkg3.FilesAnalyzed != nil || !pkg3.Analyzed() {
+		t.Errorf("Wrong filesAnalyzed: %v", pkg3.FilesAnalyzed)
+	}
+
+	stm := &goraptor.Statement{Subject: blank("pkg3"), Predicate: prefix("filesAnalyzed"), Object: literal("yes")}
+	err := parser.processTruple(stm, spdx.NewMetaL(6))
+	if perr, ok := err.(*spdx.ParseError); !ok || perr.Code != spdx.ErrInvalidValue || perr.LineStart != 6 {
+		t.Errorf("Wrong error for a non-boolean filesAnalyzed: %#v", err)
+	}
+	stm = &goraptor.Statement{Subject: blank("pkg1"), Predicate: prefix("filesAnalyzed"), Object: literal("true")}
+	if err := parser.processTruple(stm, spdx.NewMetaL(7)); err == nil {
+		t.Error("No error for filesAnalyzed defined twice.")
+	}
+}
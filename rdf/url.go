@@ -0,0 +1,56 @@
+package rdf
+
+import (
+	"fmt"
+	"github.com/vladvelici/spdx-go/spdx"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// Time allowed to ParseURL for the whole request, reading the body included.
+// Zero means no timeout.
+var URLTimeout = 30 * time.Second
+
+// Formats of the RDF media types. See formatFromContentType().
+var contentTypeFormats = map[string]string{
+	"application/rdf+xml":   FormatRDFXML,
+	"application/n-triples": FormatNTriples,
+	"text/turtle":           FormatTurtle,
+	"application/x-turtle":  FormatTurtle,
+}
+
+// Fetches the document at url with an HTTP GET and parses the response body
+// as it is received. If format is FormatGuess (or "rdf"), the format is taken
+// from the Content-Type of the response, and raptor guesses it if the media
+// type isn't a known RDF one. Responses other than 200 OK are errors.
+func ParseURL(url, format string) (*spdx.Document, error) {
+	client := &http.Client{Timeout: URLTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Fetching %s: unexpected response status %s.", url, resp.Status)
+	}
+
+	if format == FormatGuess || format == "rdf" {
+		format = formatFromContentType(resp.Header.Get("Content-Type"))
+	}
+	return Parse(resp.Body, format)
+}
+
+// Returns the format of the media type in the Content-Type header value ct,
+// or FormatGuess if it isn't one of contentTypeFormats.
+func formatFromContentType(ct string) string {
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return FormatGuess
+	}
+	if format, ok := contentTypeFormats[mediaType]; ok {
+		return format
+	}
+	return FormatGuess
+}
@@ -0,0 +1,39 @@
+package rdf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFormatFromContentType(t *testing.T) {
+	tests := map[string]string{
+		"application/rdf+xml":                FormatRDFXML,
+		"application/rdf+xml; charset=utf-8": FormatRDFXML,
+		"text/turtle;charset=UTF-8":          FormatTurtle,
+		"application/n-triples":              FormatNTriples,
+		"text/plain":                         FormatGuess,
+		"":                                   FormatGuess,
+		"not a media type; =":                FormatGuess,
+	}
+	for ct, expected := range tests {
+		if f := formatFromContentType(ct); f != expected {
+			t.Errorf("%q: found %q, expected %q.", ct, f, expected)
+		}
+	}
+}
+
+func TestParseURLStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	doc, err := ParseURL(srv.URL, FormatGuess)
+	if err == nil {
+		t.Fatal("No error for a 404 response.")
+	}
+	if doc != nil {
+		t.Errorf("Document returned for a 404 response: %#v", doc)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"github.com/vladvelici/goraptor"
 	"github.com/vladvelici/spdx-go/spdx"
+	"github.com/vladvelici/spdx-go/tag"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -42,6 +44,13 @@ func Write(output *os.File, doc *spdx.Document) error {
 	return err
 }
 
+// Writes a SPDX Document, such as one read from RDF, in the tag format. The
+// properties are written in the order of the specification, licence sets as
+// tag format licence expressions and the files of the packages after them.
+func WriteTagValue(w io.Writer, doc *spdx.Document) error {
+	return tag.Write(w, doc)
+}
+
 // Writes a SPDX Document to raptor format. format must be one of the format
 // constants (Fmt_*)
 func WriteFormat(output *os.File, doc *spdx.Document, format string) error {
@@ -134,6 +143,9 @@ func (f *Formatter) Document(doc *spdx.Document) (docId goraptor.Term, err error
 	}
 
 	docId = blank("doc")
+	if doc.Id.Val != "" {
+		docId = uri("#" + doc.Id.Val)
+	}
 
 	if err = f.setType(docId, typeDocument); err != nil {
 		return
@@ -143,6 +155,10 @@ func (f *Formatter) Document(doc *spdx.Document) (docId goraptor.Term, err error
 		return
 	}
 
+	if err = f.addLiteral(docId, "documentNamespace", doc.Namespace.Val); err != nil {
+		return
+	}
+
 	if doc.DataLicence.Val != "" {
 		if err = f.addTerm(docId, "dataLicense", uri(licenceUri+doc.DataLicence.Val)); err != nil {
 			return
@@ -327,6 +343,12 @@ func (f *Formatter) Package(pkg *spdx.Package) (id goraptor.Term, err error) {
 		}
 	}
 
+	for _, ref := range pkg.SeeAlso {
+		if err = f.addLiteral(id, "rdfs:seeAlso", ref.Val); err != nil {
+			return
+		}
+	}
+
 	err = f.Licences(id, "licenseInfoFromFiles", pkg.LicenceInfoFromFiles)
 	return
 }
@@ -400,8 +422,20 @@ func (f *Formatter) Licences(parent goraptor.Term, element string, lics []spdx.A
 // Write AnyLicence
 func (f *Formatter) Licence(licence spdx.AnyLicence) (id goraptor.Term, err error) {
 	switch lic := licence.(type) {
+	case spdx.NoAssertionLicence:
+		return prefix("noassertion"), nil
+	case spdx.NoneLicence:
+		return prefix("none"), nil
+	case *spdx.Licence:
+		return f.Licence(*lic)
 	case spdx.Licence:
 		val := lic.LicenceId()
+		switch val {
+		case spdx.NOASSERTION:
+			return prefix("noassertion"), nil
+		case spdx.NONE:
+			return prefix("none"), nil
+		}
 		if !lic.IsReference() {
 			return uri(licenceUri + val), nil
 		}
@@ -438,6 +472,52 @@ func (f *Formatter) Licence(licence spdx.AnyLicence) (id goraptor.Term, err erro
 		return id, nil
 	case *spdx.ExtractedLicence:
 		return f.ExtrLicInfo(lic)
+	case spdx.WithException:
+		id = f.newId("lic")
+		if err = f.setType(id, typeWithException); err != nil {
+			return
+		}
+		if lic.Licence != nil {
+			memberId, err := f.Licence(lic.Licence)
+			if err != nil {
+				return id, err
+			}
+			if err = f.addTerm(id, "member", memberId); err != nil {
+				return id, err
+			}
+		}
+		if lic.Exception != nil {
+			excId := f.newId("exception")
+			if err = f.setType(excId, typeLicenceException); err != nil {
+				return id, err
+			}
+			err = f.addPairs(excId,
+				pair{"licenseExceptionId", lic.Exception.Id.Val},
+				pair{"name", lic.Exception.Name.Val},
+			)
+			if err != nil {
+				return id, err
+			}
+			if err = f.addTerm(id, "licenseException", excId); err != nil {
+				return id, err
+			}
+		}
+		return id, nil
+	case *spdx.UnknownLicence:
+		id = f.newId("lic")
+		if err = f.setType(id, uri(lic.Type.Val)); err != nil {
+			return
+		}
+		for _, mem := range lic.Members {
+			memberId, err := f.Licence(mem)
+			if err != nil {
+				return id, err
+			}
+			if err = f.addTerm(id, "member", memberId); err != nil {
+				return id, err
+			}
+		}
+		return id, nil
 	}
 	return nil, errors.New("Licence type not processed. Please report this error along with the SPDX file you were processing.")
 }
@@ -546,6 +626,11 @@ func (f *Formatter) File(file *spdx.File) (id goraptor.Term, err error) {
 			return
 		}
 	}
+	for _, typ := range file.ExtraTypes {
+		if err = f.addTerm(id, "fileType", prefix(typ.Val)); err != nil {
+			return
+		}
+	}
 
 	if file.Checksum != nil {
 		cksumId, err := f.Checksum(file.Checksum)
@@ -571,6 +656,12 @@ func (f *Formatter) File(file *spdx.File) (id goraptor.Term, err error) {
 		return
 	}
 
+	for _, ref := range file.SeeAlso {
+		if err = f.addLiteral(id, "rdfs:seeAlso", ref.Val); err != nil {
+			return
+		}
+	}
+
 	err = f.Licences(id, "licenseInfoInFile", file.LicenceInfoInFile)
 	return
 }
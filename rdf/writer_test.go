@@ -0,0 +1,60 @@
+package rdf
+
+import (
+	"bytes"
+	"github.com/vladvelici/spdx-go/spdx"
+	"strings"
+	"testing"
+)
+
+func TestWriteTagValue(t *testing.T) {
+	file := &spdx.File{
+		Name:             spdx.Str("./main.go", nil),
+		Checksum:         &spdx.Checksum{Algo: spdx.Str("SHA1", nil), Value: spdx.Str("2fd4e1c67a2d28fced849ee1bb76e7391b93eb12", nil)},
+		LicenceConcluded: spdx.NewLicence("MIT", nil),
+		ArtifactOf:       []*spdx.ArtifactOf{{Name: spdx.Str("upstream", nil), ProjectUri: spdx.Str("http://example.org/upstream", nil)}},
+	}
+	doc := &spdx.Document{
+		SpecVersion: spdx.Str("SPDX-1.2", nil),
+		DataLicence: spdx.Str("CC0-1.0", nil),
+		Packages: []*spdx.Package{{
+			Name:             spdx.Str("spdx-go", nil),
+			Checksum:         &spdx.Checksum{Algo: spdx.Str("SHA1", nil), Value: spdx.Str("2fd4e1c67a2d28fced849ee1bb76e7391b93eb12", nil)},
+			LicenceConcluded: spdx.NewDisjunctiveSet(nil, spdx.NewLicence("MIT", nil), spdx.NewLicence("ISC", nil)),
+			LicenceDeclared:  spdx.NewLicence("MIT", nil),
+			Files:            []*spdx.File{file},
+		}},
+		Reviews: []*spdx.Review{{Reviewer: spdx.NewValueCreator("Person: Jane", nil), Date: spdx.NewValueDate("2014-08-06T12:00:00Z", nil)}},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WriteTagValue(buf, doc); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []string{
+		"SPDXVersion: SPDX-1.2",
+		"PackageName: spdx-go",
+		"PackageChecksum: SHA1: 2fd4e1c67a2d28fced849ee1bb76e7391b93eb12",
+		"PackageLicenseConcluded: (MIT or ISC)",
+		"PackageLicenseDeclared: MIT",
+		"FileName: ./main.go",
+		"LicenseConcluded: MIT",
+		"ArtifactOfProjectName: upstream",
+		"ArtifactOfProjectURI: http://example.org/upstream",
+		"Reviewer: Person: Jane",
+		"ReviewDate: 2014-08-06T12:00:00Z",
+	}
+	out := buf.String()
+	last := -1
+	for _, line := range expected {
+		i := strings.Index(out, line+"\n")
+		if i < 0 {
+			t.Errorf("Line %q not found in:\n%s", line, out)
+			continue
+		}
+		if i < last {
+			t.Errorf("Line %q out of order in:\n%s", line, out)
+		}
+		last = i
+	}
+}
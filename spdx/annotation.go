@@ -0,0 +1,48 @@
+package spdx
+
+// Represents an annotation of a SPDX element (SPDX 2.0).
+type Annotation struct {
+	Annotator ValueCreator // Annotator, in the `What: name (email)` format.
+	Date      ValueDate    // Annotation date.
+	Type      ValueStr     // Annotation type, one of AnnotationTypes.
+	Comment   ValueStr     // Annotation comment.
+	*Meta                  // Annotation metadata.
+}
+
+// Returns the annotation metadata.
+func (a *Annotation) M() *Meta { return a.Meta }
+
+// Checks if this Annotation is equal to `other`. Ignores metadata.
+func (a *Annotation) Equal(other *Annotation) bool {
+	return a == other || (a != nil && other != nil &&
+		a.Annotator.V() == other.Annotator.V() &&
+		a.Date.V() == other.Date.V() &&
+		a.Type.Val == other.Type.Val &&
+		a.Comment.Val == other.Comment.Val)
+}
+
+// Annotation types.
+var AnnotationTypes = []string{"REVIEW", "OTHER"}
+
+// Checks whether t is one of AnnotationTypes.
+func IsAnnotationType(t string) bool {
+	for _, at := range AnnotationTypes {
+		if t == at {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks if two slices of annotations are equal, in the same order.
+func equalAnnotations(a, b []*Annotation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, ann := range a {
+		if !ann.Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -8,17 +8,32 @@ import (
 
 // File Types
 const (
-	FT_BINARY      = "BINARY"
-	FT_SOURCE      = "SOURCE"
-	FT_ARCHIVE     = "ARCHIVE"
-	FT_OTHER       = "OTHER"
-	FT_APPLICATION = "APPLICATION"
-	FT_AUDIO       = "AUDIO"
-	FT_IMAGE       = "IMAGE"
-	FT_TEXT        = "TEXT"
-	FT_VIDEO       = "VIDEO"
+	FT_BINARY        = "BINARY"
+	FT_SOURCE        = "SOURCE"
+	FT_ARCHIVE       = "ARCHIVE"
+	FT_OTHER         = "OTHER"
+	FT_APPLICATION   = "APPLICATION"
+	FT_AUDIO         = "AUDIO"
+	FT_IMAGE         = "IMAGE"
+	FT_TEXT          = "TEXT"
+	FT_VIDEO         = "VIDEO"
+	FT_DOCUMENTATION = "DOCUMENTATION"
+	FT_SPDX          = "SPDX"
 )
 
+// All the file types, see IsFileType().
+var FileTypes = []string{FT_BINARY, FT_SOURCE, FT_ARCHIVE, FT_OTHER, FT_APPLICATION, FT_AUDIO, FT_IMAGE, FT_TEXT, FT_VIDEO, FT_DOCUMENTATION, FT_SPDX}
+
+// Checks whether t is one of FileTypes. It is case-sensitive.
+func IsFileType(t string) bool {
+	for _, ft := range FileTypes {
+		if t == ft {
+			return true
+		}
+	}
+	return false
+}
+
 // supported specification versions
 var SpecVersions = [][2]int{{1, 2}}
 
@@ -31,6 +46,26 @@ const (
 	NONE        = "NONE"
 )
 
+// Tells whether a value where NOASSERTION and NONE are allowed is one of them.
+type Sentinel int
+
+const (
+	NotSentinel         Sentinel = iota // Any other value, or no value.
+	SentinelNoAssertion                 // The value is NOASSERTION.
+	SentinelNone                        // The value is NONE.
+)
+
+// Returns the Sentinel of the value v.
+func SentinelOf(v string) Sentinel {
+	switch v {
+	case NOASSERTION:
+		return SentinelNoAssertion
+	case NONE:
+		return SentinelNone
+	}
+	return NotSentinel
+}
+
 // Interface to be used for SPDX Elements.
 // Implemented by Value(Str|Bool|Date|Creator)
 type Value interface {
@@ -145,6 +180,10 @@ func NewValueDate(val string, m *Meta) ValueDate {
 }
 
 // Store metadata about SPDX Elements
+//
+// For elements read from RDF, LineStart is the line of the statement that
+// defined the element and LineEnd the last line at which one of its properties
+// appeared.
 type Meta struct {
 	LineStart, LineEnd int
 }
@@ -184,8 +223,13 @@ func Join(a []ValueStr, sep string) string {
 // ParseError represents both parsing and lexing errors
 // It includes *spdx.Meta data (LineStart and LineEnd).
 type ParseError struct {
-	msg string
+	msg  string
+	Code ErrorCode // Kind of error, for callers that need to tell errors apart.
 	*Meta
+
+	// For conflicts, where the element conflicting with the one at Meta was
+	// defined, if known.
+	Previous *Meta
 }
 
 // Return the error message.
@@ -195,5 +239,32 @@ func (e *ParseError) Error() string {
 
 // Create a new *ParseError with the given error message and *spdx.Meta
 func NewParseError(msg string, m *Meta) *ParseError {
-	return &ParseError{msg, m}
+	return &ParseError{msg: msg, Meta: m}
+}
+
+// Create a new *ParseError with the given code, error message and *spdx.Meta
+func NewParseErrorCode(code ErrorCode, msg string, m *Meta) *ParseError {
+	return &ParseError{msg: msg, Code: code, Meta: m}
 }
+
+// Machine-readable kind of a ParseError. The values are stable and can be
+// used instead of matching the error messages.
+type ErrorCode int
+
+const (
+	ErrOther                ErrorCode = iota // No specific code.
+	ErrUnknownType                           // A node has a type that is not supported.
+	ErrIncompatibleTypes                     // A node is used with two incompatible types.
+	ErrPropertyNotSupported                  // A property is not supported for the type of its node.
+	ErrAlreadyDefined                        // A single-valued property is defined twice.
+	ErrDuplicateId                           // An SPDX identifier is defined twice.
+	ErrInvalidValue                          // A property has a value that is not allowed.
+	ErrTrailingContent                       // There is content after the end of the document.
+	ErrTooManyUnknown                        // Too many unsupported properties were found.
+	ErrDeprecated                            // A deprecated construct is used.
+	ErrLimitExceeded                         // A configured limit of the parser is exceeded.
+	ErrVerification                          // An element failed verification.
+	ErrUnboundPrefix                         // A namespace prefix is used without being bound.
+	ErrUndefinedElement                      // An element is referenced but never defined.
+	ErrUnexpectedEnd                         // The input ends unexpectedly or cannot be read to the end.
+)
@@ -0,0 +1,145 @@
+package spdx
+
+import "sort"
+
+// Deprecated SPDX Licence List IDs and the IDs replacing them.
+var deprecatedLicences = map[string]string{
+	"AGPL-1.0":      "AGPL-1.0-only",
+	"AGPL-3.0":      "AGPL-3.0-only",
+	"GFDL-1.1":      "GFDL-1.1-only",
+	"GFDL-1.2":      "GFDL-1.2-only",
+	"GFDL-1.3":      "GFDL-1.3-only",
+	"GPL-1.0":       "GPL-1.0-only",
+	"GPL-1.0+":      "GPL-1.0-or-later",
+	"GPL-2.0":       "GPL-2.0-only",
+	"GPL-2.0+":      "GPL-2.0-or-later",
+	"GPL-3.0":       "GPL-3.0-only",
+	"GPL-3.0+":      "GPL-3.0-or-later",
+	"LGPL-2.0":      "LGPL-2.0-only",
+	"LGPL-2.0+":     "LGPL-2.0-or-later",
+	"LGPL-2.1":      "LGPL-2.1-only",
+	"LGPL-2.1+":     "LGPL-2.1-or-later",
+	"LGPL-3.0":      "LGPL-3.0-only",
+	"LGPL-3.0+":     "LGPL-3.0-or-later",
+	"Nunit":         "zlib-acknowledgement",
+	"StandardML-NJ": "SMLNJ",
+}
+
+// Returns lic in canonical form: sets nested in a set of the same kind are
+// flattened into it, set members are sorted by licence ID and deprecated
+// licence IDs are replaced by their current ID. Metadata is kept.
+func CanonicalLicence(lic AnyLicence) AnyLicence {
+	switch l := lic.(type) {
+	case Licence:
+		if id, ok := deprecatedLicences[l.V()]; ok {
+			return NewLicence(id, l.Meta)
+		}
+		return l
+	case *Licence:
+		return CanonicalLicence(*l)
+	case ConjunctiveLicenceSet:
+		l.Members = canonicalMembers(l.Members, true)
+		return l
+	case *ConjunctiveLicenceSet:
+		return CanonicalLicence(*l)
+	case DisjunctiveLicenceSet:
+		l.Members = canonicalMembers(l.Members, false)
+		return l
+	case *DisjunctiveLicenceSet:
+		return CanonicalLicence(*l)
+	case WithException:
+		l.Licence = CanonicalLicence(l.Licence)
+		return l
+	case *WithException:
+		return CanonicalLicence(*l)
+	}
+	return lic
+}
+
+// Returns the canonical members of a conjunctive (if conj is set) or
+// disjunctive set.
+func canonicalMembers(members []AnyLicence, conj bool) []AnyLicence {
+	res := make([]AnyLicence, 0, len(members))
+	for _, m := range members {
+		switch c := CanonicalLicence(m).(type) {
+		case ConjunctiveLicenceSet:
+			if conj {
+				res = append(res, c.Members...)
+			} else {
+				res = append(res, c)
+			}
+		case DisjunctiveLicenceSet:
+			if !conj {
+				res = append(res, c.Members...)
+			} else {
+				res = append(res, c)
+			}
+		default:
+			res = append(res, c)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].LicenceId() < res[j].LicenceId()
+	})
+	return res
+}
+
+// Replaces all the licences of the packages and files in the document by their
+// canonical form. See CanonicalLicence().
+func (doc *Document) CanonicalizeLicences() {
+	canonical := func(list []AnyLicence) {
+		for i, lic := range list {
+			list[i] = CanonicalLicence(lic)
+		}
+	}
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		pkg.LicenceConcluded = CanonicalLicence(pkg.LicenceConcluded)
+		pkg.LicenceDeclared = CanonicalLicence(pkg.LicenceDeclared)
+		canonical(pkg.LicenceInfoFromFiles)
+	}
+	for _, file := range allFiles(doc) {
+		if file == nil {
+			continue
+		}
+		file.LicenceConcluded = CanonicalLicence(file.LicenceConcluded)
+		canonical(file.LicenceInfoInFile)
+	}
+}
+
+// Returns the conjunction of the licences declared by the packages of the
+// document, in canonical form and without duplicates: the licence that must be
+// complied with to use all the packages. NONE and NOASSERTION are left out.
+// Returns nil if no package declares a licence and the licence itself if there
+// is only one.
+func (doc *Document) RequiredLicences() AnyLicence {
+	var members []AnyLicence
+	for _, pkg := range doc.Packages {
+		if pkg == nil || pkg.LicenceDeclared == nil {
+			continue
+		}
+		if id := pkg.LicenceDeclared.LicenceId(); id == NONE || id == NOASSERTION || id == "" {
+			continue
+		}
+		members = append(members, pkg.LicenceDeclared)
+	}
+	set := CanonicalLicence(NewConjunctiveSet(nil, members...)).(ConjunctiveLicenceSet)
+
+	// members are sorted by ID, so duplicates are next to each other
+	unique := set.Members[:0]
+	for i, lic := range set.Members {
+		if i == 0 || lic.LicenceId() != set.Members[i-1].LicenceId() {
+			unique = append(unique, lic)
+		}
+	}
+	switch len(unique) {
+	case 0:
+		return nil
+	case 1:
+		return unique[0]
+	}
+	set.Members = unique
+	return set
+}
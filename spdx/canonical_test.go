@@ -0,0 +1,53 @@
+package spdx
+
+import "testing"
+
+func TestCanonicalLicence(t *testing.T) {
+	nested := NewConjunctiveSet(nil,
+		NewLicence("MIT", nil),
+		NewConjunctiveSet(nil, NewLicence("ISC", nil), NewLicence("GPL-2.0", nil)),
+		NewDisjunctiveSet(nil, NewLicence("Zlib", nil), NewLicence("Apache-2.0", nil)),
+	)
+	expected := NewConjunctiveSet(nil,
+		NewDisjunctiveSet(nil, NewLicence("Apache-2.0", nil), NewLicence("Zlib", nil)),
+		NewLicence("GPL-2.0-only", nil),
+		NewLicence("ISC", nil),
+		NewLicence("MIT", nil),
+	)
+	if res := CanonicalLicence(nested); !SameLicence(res, expected) || res.LicenceId() != expected.LicenceId() {
+		t.Errorf("Found %s (expected %s)", res.LicenceId(), expected.LicenceId())
+	}
+
+	with := NewWithException(nil, NewLicence("GPL-2.0+", nil), &LicenceException{Id: Str("Classpath-exception-2.0", nil)})
+	if res := CanonicalLicence(with); res.(WithException).Licence.LicenceId() != "GPL-2.0-or-later" {
+		t.Errorf("Deprecated licence not replaced: %s", res.LicenceId())
+	}
+}
+
+func TestRequiredLicences(t *testing.T) {
+	doc := &Document{
+		Packages: []*Package{
+			{LicenceDeclared: NewConjunctiveSet(nil, NewLicence("MIT", nil), NewLicence("ISC", nil))},
+			{LicenceDeclared: NewLicence("MIT", nil)},
+			{LicenceDeclared: NewLicence("NOASSERTION", nil)},
+			{LicenceDeclared: NewDisjunctiveSet(nil, NewLicence("Zlib", nil), NewLicence("GPL-2.0", nil))},
+			{LicenceDeclared: NewDisjunctiveSet(nil, NewLicence("GPL-2.0-only", nil), NewLicence("Zlib", nil))},
+		},
+	}
+	expected := NewConjunctiveSet(nil,
+		NewDisjunctiveSet(nil, NewLicence("GPL-2.0-only", nil), NewLicence("Zlib", nil)),
+		NewLicence("ISC", nil),
+		NewLicence("MIT", nil),
+	)
+	if res := doc.RequiredLicences(); res == nil || res.LicenceId() != expected.LicenceId() {
+		t.Errorf("Found %v (expected %s)", res, expected.LicenceId())
+	}
+
+	doc.Packages = doc.Packages[1:3]
+	if res := doc.RequiredLicences(); res == nil || res.LicenceId() != "MIT" {
+		t.Errorf("Found %v (expected MIT)", res)
+	}
+	if res := new(Document).RequiredLicences(); res != nil {
+		t.Errorf("Found %v for a document without packages.", res)
+	}
+}
@@ -0,0 +1,57 @@
+package spdx
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Columns of the CSV written by WriteCSV.
+var csvHeader = []string{
+	"Package Name",
+	"Package Version",
+	"Package Supplier",
+	"Licence Concluded",
+	"Licence Declared",
+	"Copyright Text",
+	"Package Checksum",
+}
+
+// Writes the packages of the document to w as CSV, one row per package after
+// a header row. Licences are written as their licence expression and
+// checksums as `ALGORITHM: value`. Missing values are left empty.
+func (doc *Document) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		record := []string{
+			pkg.Name.Val,
+			pkg.Version.Val,
+			pkg.Supplier.V(),
+			csvLicence(pkg.LicenceConcluded),
+			csvLicence(pkg.LicenceDeclared),
+			pkg.CopyrightText.Val,
+			"",
+		}
+		if pkg.Checksum != nil && pkg.Checksum.Value.Val != "" {
+			record[6] = pkg.Checksum.Algo.Val + ": " + pkg.Checksum.Value.Val
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Returns the licence expression of lic, or an empty string if lic is nil.
+func csvLicence(lic AnyLicence) string {
+	if lic == nil {
+		return ""
+	}
+	return lic.LicenceId()
+}
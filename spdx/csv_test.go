@@ -0,0 +1,44 @@
+package spdx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	doc := &Document{
+		Packages: []*Package{
+			{
+				Name:             Str("spdx-go", nil),
+				Version:          Str("1.0", nil),
+				Supplier:         NewValueCreator("Organization: Example, Inc.", nil),
+				LicenceConcluded: NewDisjunctiveSet(nil, NewLicence("MIT", nil), NewLicence("Apache-2.0", nil)),
+				LicenceDeclared:  NewLicence("MIT", nil),
+				CopyrightText:    Str("Copyright 2014 \"Example\"", nil),
+				Checksum:         &Checksum{Algo: Str("SHA1", nil), Value: Str("2fd4e1c67a2d28fced849ee1bb76e7391b93eb12", nil)},
+			},
+			nil,
+			{Name: Str("empty", nil)},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := doc.WriteCSV(buf); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	expected := []string{
+		"Package Name,Package Version,Package Supplier,Licence Concluded,Licence Declared,Copyright Text,Package Checksum",
+		`spdx-go,1.0,"Organization: Example, Inc.",(MIT or Apache-2.0),MIT,"Copyright 2014 ""Example""",SHA1: 2fd4e1c67a2d28fced849ee1bb76e7391b93eb12`,
+		"empty,,,,,,",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Wrong number of lines: %q", lines)
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Wrong line %d:\n%s\nexpected:\n%s", i, line, expected[i])
+		}
+	}
+}
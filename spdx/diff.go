@@ -0,0 +1,329 @@
+package spdx
+
+import "strconv"
+
+// A field-level difference between two documents.
+type Change struct {
+	Path    string // Path of the field, e.g. "Packages[0].Name".
+	Old     string // Value in the first document. Empty if missing.
+	New     string // Value in the second document. Empty if missing.
+	OldMeta *Meta  // Metadata of the value in the first document.
+	NewMeta *Meta  // Metadata of the value in the second document.
+}
+
+// Compares two documents field by field and returns the differences found.
+// Packages, reviews and list values are compared in order. Files are matched
+// by name and extracted licences by ID. Metadata is ignored.
+func Diff(a, b *Document) []Change {
+	d := new(differ)
+	d.document(a, b)
+	return d.changes
+}
+
+// Compares a document parsed from RDF with the same document parsed from
+// tag-value. Differences that only come from the formats are ignored: files
+// are listed by the package in RDF (hasFile) but by the document in tag-value,
+// so all the files in a document are compared together, regardless of where
+// they are listed.
+func CompareRDFvsTagValue(rdf, tv *Document) []Change {
+	if rdf == nil || tv == nil {
+		return Diff(rdf, tv)
+	}
+	d := new(differ)
+	d.documentFields(rdf, tv, false)
+	d.files("Files", allFiles(rdf), allFiles(tv))
+	return d.changes
+}
+
+// Returns all the files in doc and in its packages, each file only once.
+func allFiles(doc *Document) []*File {
+	seen := make(map[*File]bool)
+	var files []*File
+	add := func(list []*File) {
+		for _, f := range list {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	add(doc.Files)
+	for _, pkg := range doc.Packages {
+		if pkg != nil {
+			add(pkg.Files)
+		}
+	}
+	return files
+}
+
+// Collects changes between two documents.
+type differ struct {
+	changes []Change
+}
+
+// Returns the value and metadata of v, handling nil values.
+func valueOf(v Value) (string, *Meta) {
+	if v == nil {
+		return "", nil
+	}
+	return v.V(), v.M()
+}
+
+// Adds a change if the values of a and b differ.
+func (d *differ) value(path string, a, b Value) {
+	av, am := valueOf(a)
+	bv, bm := valueOf(b)
+	if av != bv {
+		d.changes = append(d.changes, Change{path, av, bv, am, bm})
+	}
+}
+
+// Adds a change for an element found in only one of the documents. name is
+// used as the value.
+func (d *differ) missing(path, name string, m *Meta, inOld bool) {
+	if inOld {
+		d.changes = append(d.changes, Change{Path: path, Old: name, OldMeta: m})
+	} else {
+		d.changes = append(d.changes, Change{Path: path, New: name, NewMeta: m})
+	}
+}
+
+// Compares two lists of values in order.
+func (d *differ) values(path string, a, b []Value) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var av, bv Value
+		if i < len(a) {
+			av = a[i]
+		}
+		if i < len(b) {
+			bv = b[i]
+		}
+		d.value(path+"["+strconv.Itoa(i)+"]", av, bv)
+	}
+}
+
+func strValues(list []ValueStr) []Value {
+	res := make([]Value, len(list))
+	for i := range list {
+		res[i] = list[i]
+	}
+	return res
+}
+
+func creatorValues(list []ValueCreator) []Value {
+	res := make([]Value, len(list))
+	for i := range list {
+		res[i] = list[i]
+	}
+	return res
+}
+
+func licenceValues(list []AnyLicence) []Value {
+	res := make([]Value, len(list))
+	for i := range list {
+		res[i] = list[i]
+	}
+	return res
+}
+
+func fileValues(list []*File) []Value {
+	res := make([]Value, len(list))
+	for i, f := range list {
+		if f != nil {
+			res[i] = f.Name
+		}
+	}
+	return res
+}
+
+func (d *differ) document(a, b *Document) {
+	if a == nil || b == nil {
+		if a != b {
+			d.missing("", "Document", nil, a != nil)
+		}
+		return
+	}
+	d.documentFields(a, b, true)
+}
+
+// Compares everything in a document. Files are compared only if withFiles is
+// set.
+func (d *differ) documentFields(a, b *Document, withFiles bool) {
+	d.value("Id", a.Id, b.Id)
+	d.value("SpecVersion", a.SpecVersion, b.SpecVersion)
+	d.value("Namespace", a.Namespace, b.Namespace)
+	d.value("DataLicence", a.DataLicence, b.DataLicence)
+	d.value("Comment", a.Comment, b.Comment)
+	d.creationInfo("CreationInfo", a.CreationInfo, b.CreationInfo)
+
+	for i := 0; i < len(a.Packages) || i < len(b.Packages); i++ {
+		path := "Packages[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(b.Packages):
+			d.missing(path, a.Packages[i].Name.Val, a.Packages[i].Meta, true)
+		case i >= len(a.Packages):
+			d.missing(path, b.Packages[i].Name.Val, b.Packages[i].Meta, false)
+		default:
+			d.pkg(path, a.Packages[i], b.Packages[i], withFiles)
+		}
+	}
+
+	if withFiles {
+		d.files("Files", a.Files, b.Files)
+	}
+
+	lics := make(map[string]*ExtractedLicence)
+	for _, lic := range b.ExtractedLicences {
+		lics[lic.LicenceId()] = lic
+	}
+	for _, lic := range a.ExtractedLicences {
+		path := "ExtractedLicences[" + lic.LicenceId() + "]"
+		other, ok := lics[lic.LicenceId()]
+		if !ok {
+			d.missing(path, lic.LicenceId(), lic.Meta, true)
+			continue
+		}
+		delete(lics, lic.LicenceId())
+		d.extractedLicence(path, lic, other)
+	}
+	for _, lic := range b.ExtractedLicences {
+		if _, ok := lics[lic.LicenceId()]; ok {
+			d.missing("ExtractedLicences["+lic.LicenceId()+"]", lic.LicenceId(), lic.Meta, false)
+		}
+	}
+
+	for i := 0; i < len(a.Reviews) || i < len(b.Reviews); i++ {
+		path := "Reviews[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(b.Reviews):
+			d.missing(path, a.Reviews[i].Reviewer.V(), a.Reviews[i].Meta, true)
+		case i >= len(a.Reviews):
+			d.missing(path, b.Reviews[i].Reviewer.V(), b.Reviews[i].Meta, false)
+		default:
+			d.value(path+".Reviewer", a.Reviews[i].Reviewer, b.Reviews[i].Reviewer)
+			d.value(path+".Date", a.Reviews[i].Date, b.Reviews[i].Date)
+			d.value(path+".Comment", a.Reviews[i].Comment, b.Reviews[i].Comment)
+		}
+	}
+}
+
+func (d *differ) creationInfo(path string, a, b *CreationInfo) {
+	if a == nil || b == nil {
+		if a != b {
+			d.missing(path, "CreationInfo", nil, a != nil)
+		}
+		return
+	}
+	d.values(path+".Creator", creatorValues(a.Creator), creatorValues(b.Creator))
+	d.value(path+".Created", a.Created, b.Created)
+	d.value(path+".LicenceListVersion", a.LicenceListVersion, b.LicenceListVersion)
+	d.value(path+".Comment", a.Comment, b.Comment)
+}
+
+func (d *differ) pkg(path string, a, b *Package, withFiles bool) {
+	d.value(path+".Name", a.Name, b.Name)
+	d.value(path+".Version", a.Version, b.Version)
+	d.value(path+".DownloadLocation", a.DownloadLocation, b.DownloadLocation)
+	d.value(path+".HomePage", a.HomePage, b.HomePage)
+	d.value(path+".FileName", a.FileName, b.FileName)
+	d.value(path+".Supplier", a.Supplier, b.Supplier)
+	d.value(path+".Originator", a.Originator, b.Originator)
+	d.verificationCode(path+".VerificationCode", a.VerificationCode, b.VerificationCode)
+	d.checksum(path+".Checksum", a.Checksum, b.Checksum)
+	d.value(path+".SourceInfo", a.SourceInfo, b.SourceInfo)
+	d.value(path+".LicenceConcluded", a.LicenceConcluded, b.LicenceConcluded)
+	d.values(path+".LicenceInfoFromFiles", licenceValues(a.LicenceInfoFromFiles), licenceValues(b.LicenceInfoFromFiles))
+	d.value(path+".LicenceDeclared", a.LicenceDeclared, b.LicenceDeclared)
+	d.value(path+".LicenceComments", a.LicenceComments, b.LicenceComments)
+	d.value(path+".CopyrightText", a.CopyrightText, b.CopyrightText)
+	d.values(path+".AttributionText", strValues(a.AttributionText), strValues(b.AttributionText))
+	d.value(path+".Summary", a.Summary, b.Summary)
+	d.value(path+".Description", a.Description, b.Description)
+	d.value(path+".Comment", a.Comment, b.Comment)
+	d.value(path+".PrimaryPurpose", a.PrimaryPurpose, b.PrimaryPurpose)
+	d.value(path+".ReleaseDate", a.ReleaseDate, b.ReleaseDate)
+	d.value(path+".BuiltDate", a.BuiltDate, b.BuiltDate)
+	d.value(path+".ValidUntilDate", a.ValidUntilDate, b.ValidUntilDate)
+	if withFiles {
+		d.files(path+".Files", a.Files, b.Files)
+	}
+}
+
+func (d *differ) verificationCode(path string, a, b *VerificationCode) {
+	if a == nil {
+		a = new(VerificationCode)
+	}
+	if b == nil {
+		b = new(VerificationCode)
+	}
+	d.value(path+".Value", a.Value, b.Value)
+	d.values(path+".ExcludedFiles", strValues(a.ExcludedFiles), strValues(b.ExcludedFiles))
+}
+
+func (d *differ) checksum(path string, a, b *Checksum) {
+	if a == nil {
+		a = new(Checksum)
+	}
+	if b == nil {
+		b = new(Checksum)
+	}
+	d.value(path+".Algo", a.Algo, b.Algo)
+	d.value(path+".Value", a.Value, b.Value)
+}
+
+// Compares two lists of files, matching them by name.
+func (d *differ) files(path string, a, b []*File) {
+	byName := make(map[string]*File)
+	for _, f := range b {
+		byName[f.Name.Val] = f
+	}
+	for _, f := range a {
+		fpath := path + "[" + f.Name.Val + "]"
+		other, ok := byName[f.Name.Val]
+		if !ok {
+			d.missing(fpath, f.Name.Val, f.Meta, true)
+			continue
+		}
+		delete(byName, f.Name.Val)
+		d.file(fpath, f, other)
+	}
+	for _, f := range b {
+		if _, ok := byName[f.Name.Val]; ok {
+			d.missing(path+"["+f.Name.Val+"]", f.Name.Val, f.Meta, false)
+		}
+	}
+}
+
+func (d *differ) file(path string, a, b *File) {
+	d.value(path+".Type", a.Type, b.Type)
+	d.checksum(path+".Checksum", a.Checksum, b.Checksum)
+	d.value(path+".LicenceConcluded", a.LicenceConcluded, b.LicenceConcluded)
+	d.values(path+".LicenceInfoInFile", licenceValues(a.LicenceInfoInFile), licenceValues(b.LicenceInfoInFile))
+	d.value(path+".LicenceComments", a.LicenceComments, b.LicenceComments)
+	d.value(path+".CopyrightText", a.CopyrightText, b.CopyrightText)
+	d.value(path+".Notice", a.Notice, b.Notice)
+	d.values(path+".AttributionText", strValues(a.AttributionText), strValues(b.AttributionText))
+	d.value(path+".Comment", a.Comment, b.Comment)
+	d.values(path+".Contributor", strValues(a.Contributor), strValues(b.Contributor))
+	d.values(path+".Dependency", fileValues(a.Dependency), fileValues(b.Dependency))
+	for i := 0; i < len(a.ArtifactOf) || i < len(b.ArtifactOf); i++ {
+		apath := path + ".ArtifactOf[" + strconv.Itoa(i) + "]"
+		var x, y ArtifactOf
+		if i < len(a.ArtifactOf) {
+			x = *a.ArtifactOf[i]
+		}
+		if i < len(b.ArtifactOf) {
+			y = *b.ArtifactOf[i]
+		}
+		d.value(apath+".Name", x.Name, y.Name)
+		d.value(apath+".HomePage", x.HomePage, y.HomePage)
+		d.value(apath+".ProjectUri", x.ProjectUri, y.ProjectUri)
+	}
+}
+
+func (d *differ) extractedLicence(path string, a, b *ExtractedLicence) {
+	d.values(path+".Name", strValues(a.Name), strValues(b.Name))
+	d.value(path+".Text", a.Text, b.Text)
+	d.values(path+".CrossReference", strValues(a.CrossReference), strValues(b.CrossReference))
+	d.value(path+".Comment", a.Comment, b.Comment)
+}
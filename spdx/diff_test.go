@@ -0,0 +1,65 @@
+package spdx
+
+import "testing"
+
+func diffTestDocument() *Document {
+	file := &File{
+		Name:             Str("./src/main.c", nil),
+		Type:             Str(FT_SOURCE, nil),
+		LicenceConcluded: NewLicence("MIT", nil),
+		CopyrightText:    Str(NOASSERTION, nil),
+	}
+	return &Document{
+		SpecVersion: Str("SPDX-1.2", nil),
+		DataLicence: Str("CC0-1.0", nil),
+		CreationInfo: &CreationInfo{
+			Creator: []ValueCreator{NewValueCreator("Tool: spdx-go", nil)},
+			Created: NewValueDate("2014-08-01T10:00:00Z", nil),
+		},
+		Packages: []*Package{{
+			Name:            Str("pkg", nil),
+			LicenceDeclared: NewConjunctiveSet(nil, NewLicence("MIT", nil), NewLicence("GPL-2.0", nil)),
+			Files:           []*File{file},
+		}},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a, b := diffTestDocument(), diffTestDocument()
+	if changes := Diff(a, b); len(changes) != 0 {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+
+	b.Packages[0].Version = Str("1.0", NewMetaL(7))
+	b.Files = []*File{{Name: Str("./extra.c", nil)}}
+	changes := Diff(a, b)
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes but found %+v", changes)
+	}
+	expected := Change{"Packages[0].Version", "", "1.0", nil, b.Packages[0].Version.Meta}
+	if changes[0] != expected {
+		t.Errorf("Found %+v (expected %+v)", changes[0], expected)
+	}
+	if changes[1].Path != "Files[./extra.c]" || changes[1].Old != "" || changes[1].New != "./extra.c" {
+		t.Errorf("Wrong change for missing file: %+v", changes[1])
+	}
+}
+
+func TestCompareRDFvsTagValue(t *testing.T) {
+	rdf, tv := diffTestDocument(), diffTestDocument()
+
+	// in tag-value, files are listed by the document
+	tv.Files, tv.Packages[0].Files = tv.Packages[0].Files, nil
+	if changes := CompareRDFvsTagValue(rdf, tv); len(changes) != 0 {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+
+	tv.Files[0].LicenceConcluded = NewLicence("GPL-2.0", nil)
+	changes := CompareRDFvsTagValue(rdf, tv)
+	if len(changes) != 1 {
+		t.Fatalf("Expected one change but found %+v", changes)
+	}
+	if c := changes[0]; c.Path != "Files[./src/main.c].LicenceConcluded" || c.Old != "MIT" || c.New != "GPL-2.0" {
+		t.Errorf("Wrong change: %+v", c)
+	}
+}
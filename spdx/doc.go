@@ -62,6 +62,8 @@ All licence structs implement the `AnyLicence` interface.
                             ID that starts with "LicenseRef".
     ConjunctiveLicenceSet   a list of `AnyLicence`
     DisjunctiveLicenceSet   a list of `AnyLicence`
+    WithException           a licence with a `LicenceException`, such as
+                            "GPL-2.0 WITH Classpath-exception-2.0"
 
 Validation
 ==========
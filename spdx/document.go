@@ -1,5 +1,12 @@
 package spdx
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
 const (
 	DATA_LICENCE_TAG = "CC0-1.0"
 	DATA_LICENCE_RDF = "http://spdx.org/licenses/CC0-1.0"
@@ -7,15 +14,22 @@ const (
 
 // Represents a SPDX Document.
 type Document struct {
-	SpecVersion       ValueStr            // SPDX Version
-	DataLicence       ValueStr            // Should have value DATA_LICENCE_TAG
-	CreationInfo      *CreationInfo       // Pointer to Creation Info element
-	ExtractedLicences []*ExtractedLicence // Extracted Licences found in this doc
-	Packages          []*Package          // Nested Packages
-	Files             []*File             // Files referenced in this doc
-	Comment           ValueStr            // Document comment
-	Reviews           []*Review           // Document reviews
-	*Meta                                 // Document metadata
+	Id                   ValueStr               // SPDX identifier, usually "SPDXRef-DOCUMENT"
+	SpecVersion          ValueStr               // SPDX Version
+	Namespace            ValueStr               // Document namespace (documentNamespace)
+	DataLicence          ValueStr               // Should have value DATA_LICENCE_TAG
+	CreationInfo         *CreationInfo          // Pointer to Creation Info element
+	ExtractedLicences    []*ExtractedLicence    // Extracted Licences found in this doc
+	Packages             []*Package             // Nested Packages
+	Files                []*File                // Files referenced in this doc
+	Comment              ValueStr               // Document comment
+	Reviews              []*Review              // Document reviews
+	ExternalDocumentRefs []*ExternalDocumentRef // References to other SPDX documents
+	Relationships        []*Relationship        // Relationships of the document
+	Annotations          []*Annotation          // Annotations of the document
+	Snippets             []*Snippet             // Snippets of files in this doc
+	Unknown              []*UnknownElement      // Elements of unsupported types (RDF only)
+	*Meta                                       // Document metadata
 }
 
 // Return the document metadata.
@@ -31,13 +45,20 @@ func (doc *Document) Equal(other *Document) bool {
 	if doc == nil || other == nil {
 		return false
 	}
-	eq := doc.SpecVersion.Val == other.SpecVersion.Val &&
+	eq := doc.Id.Val == other.Id.Val &&
+		doc.SpecVersion.Val == other.SpecVersion.Val &&
+		doc.Namespace.Val == other.Namespace.Val &&
 		doc.DataLicence.Val == other.DataLicence.Val &&
 		doc.CreationInfo.Equal(other.CreationInfo) &&
 		len(doc.ExtractedLicences) == len(other.ExtractedLicences) &&
 		len(doc.Packages) == len(other.Packages) &&
 		len(doc.Files) == len(other.Files) &&
 		len(doc.Reviews) == len(other.Reviews) &&
+		len(doc.ExternalDocumentRefs) == len(other.ExternalDocumentRefs) &&
+		len(doc.Relationships) == len(other.Relationships) &&
+		equalAnnotations(doc.Annotations, other.Annotations) &&
+		equalSnippets(doc.Snippets, other.Snippets) &&
+		equalUnknownElements(doc.Unknown, other.Unknown) &&
 		doc.Comment.Val == other.Comment.Val
 
 	if !eq {
@@ -64,10 +85,113 @@ func (doc *Document) Equal(other *Document) bool {
 			return false
 		}
 	}
+	for i, ref := range doc.ExternalDocumentRefs {
+		if !ref.Equal(other.ExternalDocumentRefs[i]) {
+			return false
+		}
+	}
+	for i, rel := range doc.Relationships {
+		if !rel.Equal(other.Relationships[i]) {
+			return false
+		}
+	}
 
 	return true
 }
 
+// Returns the data licence of the document as a Licence. Both the tag format
+// value ("CC0-1.0") and the RDF one (the licence URI) are accepted. Returns
+// nil if the document has no data licence.
+func (doc *Document) DataLicenceLicence() AnyLicence {
+	id := strings.TrimSpace(doc.DataLicence.Val)
+	id = strings.TrimPrefix(id, strings.TrimSuffix(DATA_LICENCE_RDF, DATA_LICENCE_TAG))
+	if id == "" {
+		return nil
+	}
+	return NewLicence(id, doc.DataLicence.Meta)
+}
+
+// Returns the distinct copyright texts of the packages and files in the
+// document, sorted. Empty texts, NONE and NOASSERTION are left out.
+func (doc *Document) CopyrightTexts() []string {
+	seen := make(map[string]bool)
+	var texts []string
+	add := func(val ValueStr) {
+		text := strings.TrimSpace(val.Val)
+		if text == "" || text == NONE || text == NOASSERTION || seen[text] {
+			return
+		}
+		seen[text] = true
+		texts = append(texts, text)
+	}
+	for _, pkg := range doc.Packages {
+		if pkg != nil {
+			add(pkg.CopyrightText)
+		}
+	}
+	for _, file := range allFiles(doc) {
+		if file != nil {
+			add(file.CopyrightText)
+		}
+	}
+	sort.Strings(texts)
+	return texts
+}
+
+// Returns every licence used in the document: the licences of the packages
+// (concluded, declared and from files), of the files (concluded and in file)
+// and the extracted licences, once for each licence ID and in the order they
+// are found. Sets are flattened to their members and a licence with an
+// exception is given by its licence. An extracted licence is returned instead
+// of the references to it.
+func AllLicences(doc *Document) []AnyLicence {
+	index := make(map[string]int)
+	var lics []AnyLicence
+	add := func(lic AnyLicence) {
+		id := lic.LicenceId()
+		if id == "" {
+			return
+		}
+		if i, ok := index[id]; ok {
+			if _, extracted := lics[i].(*ExtractedLicence); !extracted {
+				if _, ok := lic.(*ExtractedLicence); ok {
+					lics[i] = lic
+				}
+			}
+			return
+		}
+		index[id] = len(lics)
+		lics = append(lics, lic)
+	}
+	walkLicences(doc, add)
+	for _, lic := range doc.ExtractedLicences {
+		if lic != nil {
+			add(lic)
+		}
+	}
+	return lics
+}
+
+// Returns the references to external SPDX documents.
+func (doc *Document) ExternalDocuments() []*ExternalDocumentRef {
+	return doc.ExternalDocumentRefs
+}
+
+// Returns the external document reference with the given ID or nil if there
+// is no such reference. The ID can also be an element reference of the form
+// "DocumentRef-id:SPDXRef-element", in which case the part before ":" is used.
+func (doc *Document) ResolveExternalRef(documentRefId string) *ExternalDocumentRef {
+	if i := strings.Index(documentRefId, ":"); i >= 0 {
+		documentRefId = documentRefId[:i]
+	}
+	for _, ref := range doc.ExternalDocumentRefs {
+		if ref.Id.Val == documentRefId {
+			return ref
+		}
+	}
+	return nil
+}
+
 // Represents the Creation Info part of a document
 type CreationInfo struct {
 	Creator            []ValueCreator // Creator of the document
@@ -100,3 +224,105 @@ func (ci *CreationInfo) Equal(other *CreationInfo) bool {
 		ci.LicenceListVersion.Val == other.LicenceListVersion.Val &&
 		ci.Comment.Val == other.Comment.Val
 }
+
+// Represents a reference to an external SPDX document.
+type ExternalDocumentRef struct {
+	Id       ValueStr  // Reference ID, e.g. "DocumentRef-spdx-tool-1.2"
+	Document ValueStr  // SPDX document URI (namespace)
+	Checksum *Checksum // Checksum of the referenced document
+	*Meta              // External document reference metadata
+}
+
+// Returns the external document reference metadata.
+func (ref *ExternalDocumentRef) M() *Meta { return ref.Meta }
+
+// Checks if this ExternalDocumentRef is equal to `other`. Ignores metadata.
+func (ref *ExternalDocumentRef) Equal(other *ExternalDocumentRef) bool {
+	return ref == other || (ref != nil && other != nil &&
+		ref.Id.Val == other.Id.Val &&
+		ref.Document.Val == other.Document.Val &&
+		ref.Checksum.Equal(other.Checksum))
+}
+
+// Trims and lowercases the values of all the checksums of the document: those
+// of its packages, files and external document references. Returns an error
+// for every malformed checksum. See Checksum.Canonicalize().
+func (doc *Document) CanonicalizeChecksums() []*ValidationError {
+	var errs []*ValidationError
+	canonical := func(c *Checksum) {
+		if c == nil {
+			return
+		}
+		if err := c.Canonicalize(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, pkg := range doc.Packages {
+		if pkg != nil {
+			canonical(pkg.Checksum)
+		}
+	}
+	for _, file := range allFiles(doc) {
+		if file != nil {
+			canonical(file.Checksum)
+		}
+	}
+	for _, ref := range doc.ExternalDocumentRefs {
+		if ref != nil {
+			canonical(ref.Checksum)
+		}
+	}
+	return errs
+}
+
+// Returns a warning for every package whose concluded licence differs from its
+// declared licence. Licences are compared in canonical form, so the order of
+// the members of sets doesn't matter. Packages without one of the licences or
+// with NOASSERTION are skipped.
+func (doc *Document) LicenceDiscrepancies() []*ValidationError {
+	var warns []*ValidationError
+	asserted := func(lic AnyLicence) bool {
+		return lic != nil && lic.LicenceId() != NOASSERTION
+	}
+	for _, pkg := range doc.Packages {
+		if pkg == nil || !asserted(pkg.LicenceConcluded) || !asserted(pkg.LicenceDeclared) {
+			continue
+		}
+		if !SameLicence(CanonicalLicence(pkg.LicenceConcluded), CanonicalLicence(pkg.LicenceDeclared)) {
+			msg := fmt.Sprintf("Package %s concluded licence %s differs from its declared licence %s.",
+				pkg.Name.V(), pkg.LicenceConcluded.LicenceId(), pkg.LicenceDeclared.LicenceId())
+			warns = append(warns, NewVWarning(msg, pkg.Meta))
+		}
+	}
+	return warns
+}
+
+// Returns the current time, see SetClock().
+var clock = time.Now
+
+// Sets the function NewDocument() uses to get the creation time, e.g. to get
+// reproducible documents. A nil clock restores time.Now.
+func SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	clock = now
+}
+
+// Creates a new document with the latest supported SPDX version, the SPDX data
+// licence and creation info created now (see SetClock()) by the given creators,
+// in the `What: name (email)` format.
+func NewDocument(creators ...string) *Document {
+	cri := &CreationInfo{
+		Created: NewValueDate(clock().UTC().Format("2006-01-02T15:04:05Z"), nil),
+	}
+	for _, c := range creators {
+		cri.Creator = append(cri.Creator, NewValueCreator(c, nil))
+	}
+	latest := SpecVersions[len(SpecVersions)-1]
+	return &Document{
+		SpecVersion:  Str(fmt.Sprintf("SPDX-%d.%d", latest[0], latest[1]), nil),
+		DataLicence:  Str(DATA_LICENCE_TAG, nil),
+		CreationInfo: cri,
+	}
+}
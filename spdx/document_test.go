@@ -0,0 +1,168 @@
+package spdx
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolveExternalRef(t *testing.T) {
+	first := &ExternalDocumentRef{
+		Id:       Str("DocumentRef-first", nil),
+		Document: Str("http://example.org/first.spdx", nil),
+		Checksum: &Checksum{Algo: Str("SHA1", nil), Value: Str("d6a770ba38583ed4bb4525bd96e50461655d2759", nil)},
+	}
+	second := &ExternalDocumentRef{
+		Id:       Str("DocumentRef-second", nil),
+		Document: Str("http://example.org/second.spdx", nil),
+	}
+	doc := &Document{ExternalDocumentRefs: []*ExternalDocumentRef{first, second}}
+
+	if refs := doc.ExternalDocuments(); len(refs) != 2 || refs[0] != first || refs[1] != second {
+		t.Errorf("Wrong external documents: %+v", refs)
+	}
+	if ref := doc.ResolveExternalRef("DocumentRef-second"); ref != second {
+		t.Errorf("Wrong reference resolved: %+v", ref)
+	}
+	if ref := doc.ResolveExternalRef("DocumentRef-first:SPDXRef-Package"); ref != first {
+		t.Errorf("Wrong reference resolved for element ID: %+v", ref)
+	}
+	if ref := doc.ResolveExternalRef("DocumentRef-third"); ref != nil {
+		t.Errorf("Unknown reference resolved to %+v", ref)
+	}
+}
+
+func TestDataLicenceLicence(t *testing.T) {
+	for _, val := range []string{DATA_LICENCE_TAG, DATA_LICENCE_RDF, " CC0-1.0 "} {
+		doc := &Document{DataLicence: Str(val, nil)}
+		lic, ok := doc.DataLicenceLicence().(Licence)
+		if !ok || !lic.Equal(NewLicence("CC0-1.0", nil)) {
+			t.Errorf("Wrong data licence for %#v: %#v", val, doc.DataLicenceLicence())
+		}
+	}
+	if lic := new(Document).DataLicenceLicence(); lic != nil {
+		t.Errorf("Found data licence %#v in an empty document.", lic)
+	}
+}
+
+func TestCopyrightTexts(t *testing.T) {
+	doc := &Document{
+		Packages: []*Package{
+			{
+				CopyrightText: Str("Copyright 2014 Example Ltd.", nil),
+				Files: []*File{
+					{CopyrightText: Str("Copyright 2013 Someone", nil)},
+					{CopyrightText: Str(NOASSERTION, nil)},
+				},
+			},
+		},
+		Files: []*File{
+			{CopyrightText: Str("Copyright 2014 Example Ltd.", nil)},
+			{CopyrightText: Str(NONE, nil)},
+		},
+	}
+	expected := []string{"Copyright 2013 Someone", "Copyright 2014 Example Ltd."}
+	if texts := doc.CopyrightTexts(); !reflect.DeepEqual(texts, expected) {
+		t.Errorf("Found %#v (expected %#v)", texts, expected)
+	}
+}
+
+func TestAllLicences(t *testing.T) {
+	extracted := &ExtractedLicence{Id: Str("LicenseRef-1", nil), Text: Str("Text", nil)}
+	doc := &Document{
+		Packages: []*Package{
+			{
+				LicenceConcluded: NewConjunctiveSet(nil,
+					NewLicence("MIT", nil),
+					NewDisjunctiveSet(nil, NewLicence("GPL-2.0", nil), NewLicence("LicenseRef-1", nil)),
+				),
+				LicenceDeclared:      NewLicence("MIT", nil),
+				LicenceInfoFromFiles: []AnyLicence{NewLicence("Apache-2.0", nil)},
+				Files: []*File{
+					{LicenceConcluded: NewLicence("GPL-2.0", nil)},
+				},
+			},
+		},
+		Files: []*File{
+			{LicenceInfoInFile: []AnyLicence{NewLicence("BSD-3-Clause", nil)}},
+		},
+		ExtractedLicences: []*ExtractedLicence{extracted},
+	}
+
+	lics := AllLicences(doc)
+	expected := []string{"MIT", "GPL-2.0", "LicenseRef-1", "Apache-2.0", "BSD-3-Clause"}
+	if len(lics) != len(expected) {
+		t.Fatalf("Found %d licences (expected %d): %v", len(lics), len(expected), lics)
+	}
+	for i, id := range expected {
+		if lics[i].LicenceId() != id {
+			t.Errorf("Licence %d: found %s (expected %s)", i, lics[i].LicenceId(), id)
+		}
+	}
+	if lics[2] != extracted {
+		t.Errorf("Reference returned instead of the extracted licence: %#v", lics[2])
+	}
+}
+
+func TestCanonicalizeChecksums(t *testing.T) {
+	sha1 := &Checksum{Algo: Str("SHA1", nil), Value: Str(" D6A770BA38583ED4bb4525bd96e50461655d2759\n", nil)}
+	md5 := &Checksum{Algo: Str("MD5", nil), Value: Str("0123456789ABCDEF", nil), Meta: NewMetaL(7)}
+	doc := &Document{
+		Packages: []*Package{{Checksum: sha1, Files: []*File{{Checksum: md5}}}},
+		ExternalDocumentRefs: []*ExternalDocumentRef{
+			{Checksum: &Checksum{Algo: Str("SHA256", nil), Value: Str("not hex", nil)}},
+		},
+	}
+
+	errs := doc.CanonicalizeChecksums()
+	if sha1.Value.Val != "d6a770ba38583ed4bb4525bd96e50461655d2759" {
+		t.Errorf("Wrong canonical checksum %#v", sha1.Value.Val)
+	}
+	if md5.Value.Val != "0123456789abcdef" {
+		t.Errorf("Wrong canonical checksum %#v", md5.Value.Val)
+	}
+	if len(errs) != 2 || errs[0].Meta != md5.Meta {
+		t.Errorf("Wrong errors: %v", errs)
+	}
+}
+
+func TestLicenceDiscrepancies(t *testing.T) {
+	mit, isc := NewLicence("MIT", nil), NewLicence("ISC", nil)
+	differ := &Package{
+		Name:             Str("differ", nil),
+		LicenceConcluded: NewConjunctiveSet(nil, mit, isc),
+		LicenceDeclared:  mit,
+		Meta:             NewMetaL(3),
+	}
+	doc := &Document{
+		Packages: []*Package{
+			differ,
+			{LicenceConcluded: NewDisjunctiveSet(nil, isc, mit), LicenceDeclared: NewDisjunctiveSet(nil, mit, isc)},
+			{LicenceConcluded: NewLicence(NOASSERTION, nil), LicenceDeclared: mit},
+			{LicenceConcluded: isc},
+		},
+	}
+	warns := doc.LicenceDiscrepancies()
+	if len(warns) != 1 || warns[0].Type != ValidWarning || warns[0].Meta != differ.Meta {
+		t.Errorf("Wrong discrepancies: %v", warns)
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	SetClock(func() time.Time { return time.Date(2014, 8, 26, 12, 30, 0, 0, time.FixedZone("CEST", 7200)) })
+	defer SetClock(nil)
+
+	doc := NewDocument("Tool: spdx-go", "Person: Jane Doe (jane@example.org)")
+	if doc.SpecVersion.Val != "SPDX-1.2" || doc.DataLicence.Val != DATA_LICENCE_TAG {
+		t.Errorf("Wrong document: %#v", doc)
+	}
+	if created := doc.CreationInfo.Created; created.V() != "2014-08-26T10:30:00Z" || created.Time() == nil {
+		t.Errorf("Wrong creation time %#v", created.V())
+	}
+	if len(doc.CreationInfo.Creator) != 2 || doc.CreationInfo.Creator[1].Email() != "jane@example.org" {
+		t.Errorf("Wrong creators: %#v", doc.CreationInfo.Creator)
+	}
+	if v := NewValidator(); !v.Document(doc) {
+		t.Errorf("New document is not valid: %v", v.Errors())
+	}
+}
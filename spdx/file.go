@@ -1,20 +1,42 @@
 package spdx
 
+import "strings"
+
 // Represents a SPDX File.
 type File struct {
-	Name              ValueStr      // File name.
-	Type              ValueStr      // File type.
-	Checksum          *Checksum     // File Checksum.
-	LicenceConcluded  AnyLicence    // Licence Concluded. NOASSERTION and NONE values allowed
-	LicenceInfoInFile []AnyLicence  // Licence Info in File. NOASSERTION and NONE values allowed
-	LicenceComments   ValueStr      // Licence comments.
-	CopyrightText     ValueStr      // File copyright text NOASSERTION and NONE allowed.
-	Notice            ValueStr      // File notice.
-	ArtifactOf        []*ArtifactOf // A list of artifacts
-	Dependency        []*File       // File dependecies.
-	Contributor       []ValueStr    // File contributors.
-	Comment           ValueStr      // File comments.
-	*Meta                           // File metadata.
+	Name              ValueStr        // File name.
+	OriginalName      ValueStr        // File name as found in the input, if it was normalized (RDF only).
+	Type              ValueStr        // File type.
+	ExtraTypes        []ValueStr      // File types after the first one (RDF only).
+	Checksum          *Checksum       // File Checksum.
+	LicenceConcluded  AnyLicence      // Licence Concluded. NOASSERTION and NONE values allowed
+	LicenceInfoInFile []AnyLicence    // Licence Info in File. NOASSERTION and NONE values allowed
+	LicenceComments   ValueStr        // Licence comments.
+	CopyrightText     ValueStr        // File copyright text NOASSERTION and NONE allowed.
+	CopyrightSentinel Sentinel        // Whether CopyrightText is NOASSERTION or NONE (RDF only).
+	Notice            ValueStr        // File notice.
+	AttributionText   []ValueStr      // Attribution texts (SPDX 2.2).
+	ArtifactOf        []*ArtifactOf   // A list of artifacts
+	Dependency        []*File         // File dependecies.
+	Contributor       []ValueStr      // File contributors.
+	Comment           ValueStr        // File comments.
+	SeeAlso           []ValueStr      // Related resources (rdfs:seeAlso, RDF only).
+	Relationships     []*Relationship // Relationships of the file.
+	Annotations       []*Annotation   // Annotations of the file.
+	*Meta                             // File metadata.
+}
+
+// Returns name as a SPDX file name: with forward slashes and starting with
+// "./". For example, src\a.c and /src/a.c become ./src/a.c.
+func NormalizeFileName(name string) string {
+	name = strings.Replace(name, "\\", "/", -1)
+	switch {
+	case strings.HasPrefix(name, "./"):
+		return name
+	case strings.HasPrefix(name, "/"):
+		return "." + name
+	}
+	return "./" + name
 }
 
 // Returns the File metadata.
@@ -37,7 +59,12 @@ func (f *File) Equal(other *File) bool {
 		len(f.LicenceInfoInFile) == len(other.LicenceInfoInFile) &&
 		len(f.ArtifactOf) == len(other.ArtifactOf) &&
 		len(f.Dependency) == len(other.Dependency) &&
-		len(f.Contributor) == len(other.Contributor))
+		len(f.Contributor) == len(other.Contributor) &&
+		len(f.SeeAlso) == len(other.SeeAlso) &&
+		len(f.AttributionText) == len(other.AttributionText) &&
+		len(f.ExtraTypes) == len(other.ExtraTypes) &&
+		len(f.Relationships) == len(other.Relationships) &&
+		equalAnnotations(f.Annotations, other.Annotations))
 	if !eq {
 		return false
 	}
@@ -61,14 +88,57 @@ func (f *File) Equal(other *File) bool {
 			return false
 		}
 	}
+	for i, v := range f.SeeAlso {
+		if v.Val != other.SeeAlso[i].Val {
+			return false
+		}
+	}
+	for i, v := range f.AttributionText {
+		if v.Val != other.AttributionText[i].Val {
+			return false
+		}
+	}
+	for i, v := range f.ExtraTypes {
+		if v.Val != other.ExtraTypes[i].Val {
+			return false
+		}
+	}
+	for i, rel := range f.Relationships {
+		if !rel.Equal(other.Relationships[i]) {
+			return false
+		}
+	}
 	return true
 }
 
+// Returns the projects the file comes from, whether they are given by the
+// ArtifactOf properties (SPDX 1.x and 2.0) or by GENERATED_FROM relationships
+// (SPDX 2.1). Relationships are returned as ArtifactOf elements with the URI
+// of the related element as ProjectUri and, if the related element is a
+// package of the document, its name and home page.
+func (f *File) Origins() []*ArtifactOf {
+	origins := make([]*ArtifactOf, 0, len(f.ArtifactOf))
+	origins = append(origins, f.ArtifactOf...)
+	for _, rel := range f.Relationships {
+		if rel == nil || rel.Type.Val != "GENERATED_FROM" {
+			continue
+		}
+		artif := &ArtifactOf{ProjectUri: rel.Related, Meta: rel.Meta}
+		if pkg, ok := rel.Element.(*Package); ok {
+			artif.Name, artif.HomePage = pkg.Name, pkg.HomePage
+		}
+		origins = append(origins, artif)
+	}
+	return origins
+}
+
 // Represents the ArtifactOf* properties of a SPDX File.
 type ArtifactOf struct {
 	ProjectUri ValueStr // Project URI
 	HomePage   ValueStr // Project HomePage
 	Name       ValueStr // Project Name
+	Revision   ValueStr // Project Revision (doap:revision, RDF only)
+	Wiki       ValueStr // Project Wiki (doap:wiki, RDF only)
 	*Meta               // Artifact metadata.
 }
 
@@ -80,5 +150,7 @@ func (a *ArtifactOf) Equal(o *ArtifactOf) bool {
 	return a == o || (a != nil && o != nil &&
 		a.ProjectUri.Val == o.ProjectUri.Val &&
 		a.HomePage.Val == o.HomePage.Val &&
-		a.Name.Val == o.Name.Val)
+		a.Name.Val == o.Name.Val &&
+		a.Revision.Val == o.Revision.Val &&
+		a.Wiki.Val == o.Wiki.Val)
 }
@@ -0,0 +1,19 @@
+package spdx
+
+import "testing"
+
+func TestNormalizeFileName(t *testing.T) {
+	tests := map[string]string{
+		"./src/a.c":   "./src/a.c",
+		"src/a.c":     "./src/a.c",
+		"/src/a.c":    "./src/a.c",
+		"src\\a.c":    "./src/a.c",
+		".\\src\\a.c": "./src/a.c",
+		"a.c":         "./a.c",
+	}
+	for name, expected := range tests {
+		if res := NormalizeFileName(name); res != expected {
+			t.Errorf("%s: found %s (expected %s)", name, res, expected)
+		}
+	}
+}
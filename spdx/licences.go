@@ -36,13 +36,30 @@ func NewLicence(id string, m *Meta) Licence {
 	return Licence{Str(id, m)}
 }
 
+// The NOASSERTION licence: no assertion is made about the licence. Its
+// LicenceId() is NOASSERTION, so that it compares equal by ID to a Licence
+// named NOASSERTION, as read from the tag format.
+type NoAssertionLicence struct{ *Meta }
+
+func (l NoAssertionLicence) LicenceId() string { return NOASSERTION }
+func (l NoAssertionLicence) V() string         { return NOASSERTION }
+func (l NoAssertionLicence) M() *Meta          { return l.Meta }
+
+// The NONE licence: there is no licence. Its LicenceId() is NONE.
+type NoneLicence struct{ *Meta }
+
+func (l NoneLicence) LicenceId() string { return NONE }
+func (l NoneLicence) V() string         { return NONE }
+func (l NoneLicence) M() *Meta          { return l.Meta }
+
 // Represents an Extracted Licence.
 type ExtractedLicence struct {
 	Id             ValueStr
-	Name           []ValueStr
+	Name           []ValueStr // Licence names, from spdx:name or rdfs:label in RDF.
 	Text           ValueStr
 	CrossReference []ValueStr
 	Comment        ValueStr
+	NodeId         string // Identifier of the blank node the licence was read from, if any.
 	*Meta
 }
 
@@ -51,7 +68,8 @@ func (l *ExtractedLicence) LicenceId() string { return l.Id.V() }
 func (l *ExtractedLicence) V() string         { return l.LicenceId() }
 func (l *ExtractedLicence) M() *Meta          { return l.Meta }
 
-// Checks if this ExtractedLicence is equal to `other`. Ignores metadata.
+// Checks if this ExtractedLicence is equal to `other`. Ignores metadata and
+// NodeId.
 // Slice elements must be in the same order for this function to return true.
 func (l *ExtractedLicence) Equal(other *ExtractedLicence) bool {
 	if l == other {
@@ -85,6 +103,7 @@ func (l *ExtractedLicence) Equal(other *ExtractedLicence) bool {
 // DisjunctiveLicenceSet are aliases for LicenceSet.
 type LicenceSet struct {
 	Members []AnyLicence
+	NodeId  string // Blank node identifier or URI of the node the set was read from, if any.
 	*Meta
 }
 
@@ -95,7 +114,7 @@ func (s *LicenceSet) M() *Meta           { return s.Meta }
 type ConjunctiveLicenceSet LicenceSet
 
 func NewConjunctiveSet(meta *Meta, lics ...AnyLicence) ConjunctiveLicenceSet {
-	return ConjunctiveLicenceSet{lics, meta}
+	return ConjunctiveLicenceSet{Members: lics, Meta: meta}
 }
 func (c ConjunctiveLicenceSet) LicenceId() string { return join(c.Members, " and ") }
 func (c ConjunctiveLicenceSet) V() string         { return c.LicenceId() }
@@ -108,7 +127,7 @@ func (c *ConjunctiveLicenceSet) Add(lic AnyLicence) { c.Members = append(c.Membe
 type DisjunctiveLicenceSet LicenceSet
 
 func NewDisjunctiveSet(meta *Meta, lics ...AnyLicence) DisjunctiveLicenceSet {
-	return DisjunctiveLicenceSet{lics, meta}
+	return DisjunctiveLicenceSet{Members: lics, Meta: meta}
 }
 func (c DisjunctiveLicenceSet) LicenceId() string { return join(c.Members, " or ") }
 func (c DisjunctiveLicenceSet) V() string         { return c.LicenceId() }
@@ -117,6 +136,57 @@ func (c DisjunctiveLicenceSet) M() *Meta          { return c.Meta }
 // Add a licence to the set.
 func (c *DisjunctiveLicenceSet) Add(lic AnyLicence) { c.Members = append(c.Members, lic) }
 
+// Represents a licence exception, such as "Classpath-exception-2.0".
+type LicenceException struct {
+	Id   ValueStr
+	Name ValueStr
+	*Meta
+}
+
+// Returns the licence exception metadata.
+func (e *LicenceException) M() *Meta { return e.Meta }
+
+// Compares two licence exceptions, ignoring their metadata.
+func (e *LicenceException) Equal(other *LicenceException) bool {
+	return e == other || (e != nil && other != nil && e.Id.Val == other.Id.Val && e.Name.Val == other.Name.Val)
+}
+
+// A licence with an exception, e.g. "GPL-2.0 WITH Classpath-exception-2.0".
+type WithException struct {
+	Licence   AnyLicence
+	Exception *LicenceException
+	*Meta
+}
+
+func NewWithException(meta *Meta, lic AnyLicence, exception *LicenceException) WithException {
+	return WithException{lic, exception, meta}
+}
+func (w WithException) LicenceId() string {
+	id := "()"
+	if w.Licence != nil {
+		id = w.Licence.LicenceId()
+	}
+	if w.Exception != nil {
+		id += " with " + w.Exception.Id.V()
+	}
+	return id
+}
+func (w WithException) V() string { return w.LicenceId() }
+func (w WithException) M() *Meta  { return w.Meta }
+
+// A licence of a type that is not supported, such as a licence operator
+// introduced by a newer version of the SPDX specification. Type is the type as
+// found in the document and Members are the licences it applies to, if any.
+type UnknownLicence struct {
+	Type    ValueStr
+	Members []AnyLicence
+	*Meta
+}
+
+func (u *UnknownLicence) LicenceId() string { return u.Type.V() + join(u.Members, " ") }
+func (u *UnknownLicence) V() string         { return u.LicenceId() }
+func (u *UnknownLicence) M() *Meta          { return u.Meta }
+
 // Useful functions for working with licences
 
 // Join the IDs for given licences by separator. Similar
@@ -151,9 +221,14 @@ func SameLicence(a, b AnyLicence) bool {
 	if a == nil || b == nil {
 		return false
 	}
+	if tb, ok := b.(*Licence); ok {
+		b = *tb
+	}
 	switch ta := a.(type) {
 	default:
 		return false
+	case *Licence:
+		return SameLicence(*ta, b)
 	case Licence:
 		if tb, ok := b.(Licence); ok && ta.Equal(tb) {
 			return true
@@ -186,5 +261,33 @@ func SameLicence(a, b AnyLicence) bool {
 			return true
 		}
 		return false
+	case NoAssertionLicence:
+		_, ok := b.(NoAssertionLicence)
+		return ok
+	case NoneLicence:
+		_, ok := b.(NoneLicence)
+		return ok
+	case WithException:
+		if tb, ok := b.(WithException); ok {
+			return SameLicence(ta.Licence, tb.Licence) && ta.Exception.Equal(tb.Exception)
+		}
+		return false
+	case *UnknownLicence:
+		tb, ok := b.(*UnknownLicence)
+		if !ok {
+			return false
+		}
+		if ta == tb {
+			return true
+		}
+		if ta == nil || tb == nil || ta.Type.Val != tb.Type.Val || len(ta.Members) != len(tb.Members) {
+			return false
+		}
+		for i, lica := range ta.Members {
+			if !SameLicence(lica, tb.Members[i]) {
+				return false
+			}
+		}
+		return true
 	}
 }
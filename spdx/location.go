@@ -0,0 +1,119 @@
+package spdx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A package download location decomposed, see ParseDownloadLocation(). For
+// example, "git+https://git.example.org/proj.git@v1.0#src" has the Tool "git",
+// the Transport "https", the URL "https://git.example.org/proj.git", the
+// Revision "v1.0" and the SubPath "src".
+type DownloadLocation struct {
+	Tool      string   // VCS tool, one of VCSTools. Empty for locations that aren't VCS ones.
+	Transport string   // URL scheme, e.g. "https". Empty for SCP-like locations ("git@host:path").
+	URL       string   // Location without the tool, the revision and the sub path.
+	Revision  string   // Revision, tag or branch.
+	SubPath   string   // Path inside the repository or archive.
+	Sentinel  Sentinel // Whether the location is NOASSERTION or NONE. The other fields are then empty.
+}
+
+// VCS tools allowed in download locations.
+var VCSTools = []string{"git", "hg", "svn", "bzr"}
+
+// Checks whether tool is one of VCSTools.
+func IsVCSTool(tool string) bool {
+	for _, t := range VCSTools {
+		if tool == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Parses a package download location with the grammar of the SPDX
+// specification: `[<vcs_tool>+]<transport>://<host>[/<path>][@<revision>][#<sub_path>]`,
+// or `<vcs_tool>+<user>@<host>:<path>[@<revision>][#<sub_path>]` for SCP-like
+// locations. NOASSERTION and NONE are returned as sentinels. A revision is only
+// allowed with a VCS tool.
+func ParseDownloadLocation(s string) (DownloadLocation, error) {
+	var loc DownloadLocation
+	s = strings.TrimSpace(s)
+	if loc.Sentinel = SentinelOf(s); loc.Sentinel != NotSentinel {
+		return loc, nil
+	}
+	malformed := fmt.Errorf("Malformed download location %q.", s)
+
+	rest := s
+	if i := strings.Index(rest, "+"); i >= 0 && IsVCSTool(rest[:i]) {
+		loc.Tool = rest[:i]
+		rest = rest[i+1:]
+	}
+	if i := strings.Index(rest, "#"); i >= 0 {
+		loc.SubPath = rest[i+1:]
+		rest = rest[:i]
+		if loc.SubPath == "" {
+			return DownloadLocation{}, malformed
+		}
+	}
+
+	// start of the path, where a revision can be
+	var path int
+	if i := strings.Index(rest, "://"); i >= 0 {
+		loc.Transport = rest[:i]
+		if !isScheme(loc.Transport) {
+			return DownloadLocation{}, malformed
+		}
+		host := rest[i+3:]
+		if j := strings.Index(host, "/"); j >= 0 {
+			host = host[:j]
+			path = i + 3 + j
+		} else {
+			path = len(rest)
+		}
+		if host == "" {
+			return DownloadLocation{}, malformed
+		}
+	} else {
+		// SCP-like locations are only allowed with a VCS tool
+		i := strings.Index(rest, ":")
+		if loc.Tool == "" || i <= 0 {
+			return DownloadLocation{}, malformed
+		}
+		path = i
+	}
+
+	if loc.Tool != "" {
+		if i := strings.LastIndex(rest[path:], "@"); i >= 0 {
+			loc.Revision = rest[path+i+1:]
+			rest = rest[:path+i]
+			if loc.Revision == "" {
+				return DownloadLocation{}, malformed
+			}
+		}
+	}
+	loc.URL = rest
+	return loc, nil
+}
+
+// Checks whether s is a valid URL scheme.
+func isScheme(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the decomposed download location of the package, see
+// ParseDownloadLocation().
+func (pkg *Package) ParsedDownloadLocation() (DownloadLocation, error) {
+	return ParseDownloadLocation(pkg.DownloadLocation.Val)
+}
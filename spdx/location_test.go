@@ -0,0 +1,54 @@
+package spdx
+
+import "testing"
+
+func TestParseDownloadLocation(t *testing.T) {
+	tests := map[string]DownloadLocation{
+		"NOASSERTION":                           {Sentinel: SentinelNoAssertion},
+		"NONE":                                  {Sentinel: SentinelNone},
+		"http://ftp.gnu.org/gnu/glibc-2.11.tar": {Transport: "http", URL: "http://ftp.gnu.org/gnu/glibc-2.11.tar"},
+		"git+https://git.myproject.org/MyProject.git@v1.0#src/lib": {
+			Tool: "git", Transport: "https", URL: "https://git.myproject.org/MyProject.git", Revision: "v1.0", SubPath: "src/lib",
+		},
+		"git+ssh://git@git.myproject.org/MyProject.git": {
+			Tool: "git", Transport: "ssh", URL: "ssh://git@git.myproject.org/MyProject.git",
+		},
+		"git+git@git.myproject.org:MyProject@master": {
+			Tool: "git", URL: "git@git.myproject.org:MyProject", Revision: "master",
+		},
+		"hg+https://hg.myproject.org/MyProject#subdir": {
+			Tool: "hg", Transport: "https", URL: "https://hg.myproject.org/MyProject", SubPath: "subdir",
+		},
+		"svn+svn://svn.myproject.org/svn/MyProject@2019": {
+			Tool: "svn", Transport: "svn", URL: "svn://svn.myproject.org/svn/MyProject", Revision: "2019",
+		},
+	}
+	for s, expected := range tests {
+		loc, err := ParseDownloadLocation(s)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %s", s, err)
+		} else if loc != expected {
+			t.Errorf("%s: found %+v (expected %+v)", s, loc, expected)
+		}
+	}
+
+	malformed := []string{
+		"",
+		"ftp.gnu.org/gnu/glibc-2.11.tar",
+		"git+https://",
+		"git+https://git.myproject.org/MyProject.git@",
+		"https://example.org/archive.tar#",
+		"1http://example.org",
+		"user@host:path",
+	}
+	for _, s := range malformed {
+		if _, err := ParseDownloadLocation(s); err == nil {
+			t.Errorf("No error for %q.", s)
+		}
+	}
+
+	pkg := &Package{DownloadLocation: Str("git+https://example.org/p.git@v2", nil)}
+	if loc, err := pkg.ParsedDownloadLocation(); err != nil || loc.Revision != "v2" {
+		t.Errorf("Wrong parsed download location %+v, %v", loc, err)
+	}
+}
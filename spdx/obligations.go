@@ -0,0 +1,144 @@
+package spdx
+
+import "sort"
+
+// Licence obligation categories used by Document.LicenceObligations().
+const (
+	ObligationPermissive     = "permissive"
+	ObligationCopyleftWeak   = "copyleft-weak"
+	ObligationCopyleftStrong = "copyleft-strong"
+	ObligationUnknown        = "unknown" // proprietary or not classified
+)
+
+// Obligation category of common SPDX Licence List licences, by current
+// licence ID. Licences that are not in this table are classified as
+// ObligationUnknown.
+var licenceObligations = map[string]string{
+	"0BSD":         ObligationPermissive,
+	"Apache-1.0":   ObligationPermissive,
+	"Apache-1.1":   ObligationPermissive,
+	"Apache-2.0":   ObligationPermissive,
+	"Artistic-2.0": ObligationPermissive,
+	"BSD-2-Clause": ObligationPermissive,
+	"BSD-3-Clause": ObligationPermissive,
+	"BSD-4-Clause": ObligationPermissive,
+	"BSL-1.0":      ObligationPermissive,
+	"CC0-1.0":      ObligationPermissive,
+	"ISC":          ObligationPermissive,
+	"MIT":          ObligationPermissive,
+	"PostgreSQL":   ObligationPermissive,
+	"Python-2.0":   ObligationPermissive,
+	"Unlicense":    ObligationPermissive,
+	"WTFPL":        ObligationPermissive,
+	"X11":          ObligationPermissive,
+	"Zlib":         ObligationPermissive,
+
+	"CDDL-1.0":          ObligationCopyleftWeak,
+	"CDDL-1.1":          ObligationCopyleftWeak,
+	"CPL-1.0":           ObligationCopyleftWeak,
+	"EPL-1.0":           ObligationCopyleftWeak,
+	"EPL-2.0":           ObligationCopyleftWeak,
+	"LGPL-2.0-only":     ObligationCopyleftWeak,
+	"LGPL-2.0-or-later": ObligationCopyleftWeak,
+	"LGPL-2.1-only":     ObligationCopyleftWeak,
+	"LGPL-2.1-or-later": ObligationCopyleftWeak,
+	"LGPL-3.0-only":     ObligationCopyleftWeak,
+	"LGPL-3.0-or-later": ObligationCopyleftWeak,
+	"MPL-1.0":           ObligationCopyleftWeak,
+	"MPL-1.1":           ObligationCopyleftWeak,
+	"MPL-2.0":           ObligationCopyleftWeak,
+	"MS-RL":             ObligationCopyleftWeak,
+
+	"AGPL-1.0-only":     ObligationCopyleftStrong,
+	"AGPL-3.0-only":     ObligationCopyleftStrong,
+	"AGPL-3.0-or-later": ObligationCopyleftStrong,
+	"GPL-1.0-only":      ObligationCopyleftStrong,
+	"GPL-1.0-or-later":  ObligationCopyleftStrong,
+	"GPL-2.0-only":      ObligationCopyleftStrong,
+	"GPL-2.0-or-later":  ObligationCopyleftStrong,
+	"GPL-3.0-only":      ObligationCopyleftStrong,
+	"GPL-3.0-or-later":  ObligationCopyleftStrong,
+	"OSL-3.0":           ObligationCopyleftStrong,
+	"Sleepycat":         ObligationCopyleftStrong,
+}
+
+// Returns the obligation category of the licence with the given ID. Deprecated
+// IDs are classified as their current ID.
+func LicenceObligation(id string) string {
+	if current, ok := deprecatedLicences[id]; ok {
+		id = current
+	}
+	if cat, ok := licenceObligations[id]; ok {
+		return cat
+	}
+	return ObligationUnknown
+}
+
+// Groups the licences used in the document (concluded, declared and found in
+// packages and files) by obligation category. Each category maps to the
+// sorted IDs of its licences. Licences in sets are classified individually, a
+// licence with an exception is classified by its licence, and NONE and
+// NOASSERTION are left out.
+func (doc *Document) LicenceObligations() map[string][]string {
+	seen := make(map[string]bool)
+	res := make(map[string][]string)
+	walkLicences(doc, func(lic AnyLicence) {
+		id := lic.LicenceId()
+		if id == NONE || id == NOASSERTION || seen[id] {
+			return
+		}
+		seen[id] = true
+		cat := LicenceObligation(id)
+		res[cat] = append(res[cat], id)
+	})
+	for _, ids := range res {
+		sort.Strings(ids)
+	}
+	return res
+}
+
+// Calls f for every licence used in the document's packages and files. Sets
+// and exceptions are not passed to f, but their licences are.
+func walkLicences(doc *Document, f func(AnyLicence)) {
+	var walk func(AnyLicence)
+	walk = func(lic AnyLicence) {
+		switch l := lic.(type) {
+		case nil:
+		case ConjunctiveLicenceSet:
+			walkAll(l.Members, walk)
+		case *ConjunctiveLicenceSet:
+			walkAll(l.Members, walk)
+		case DisjunctiveLicenceSet:
+			walkAll(l.Members, walk)
+		case *DisjunctiveLicenceSet:
+			walkAll(l.Members, walk)
+		case WithException:
+			walk(l.Licence)
+		case *WithException:
+			walk(l.Licence)
+		default:
+			f(lic)
+		}
+	}
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		walk(pkg.LicenceConcluded)
+		walk(pkg.LicenceDeclared)
+		walkAll(pkg.LicenceInfoFromFiles, walk)
+	}
+	for _, file := range allFiles(doc) {
+		if file == nil {
+			continue
+		}
+		walk(file.LicenceConcluded)
+		walkAll(file.LicenceInfoInFile, walk)
+	}
+}
+
+func walkAll(list []AnyLicence, f func(AnyLicence)) {
+	for _, lic := range list {
+		f(lic)
+	}
+}
@@ -0,0 +1,55 @@
+package spdx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLicenceObligations(t *testing.T) {
+	doc := &Document{
+		Packages: []*Package{
+			{
+				LicenceConcluded: NewDisjunctiveSet(nil, NewLicence("MIT", nil), NewLicence("GPL-2.0", nil)),
+				LicenceDeclared:  NewLicence("NOASSERTION", nil),
+				Files: []*File{
+					{LicenceConcluded: NewLicence("LicenseRef-1", nil)},
+				},
+			},
+		},
+		Files: []*File{
+			{LicenceInfoInFile: []AnyLicence{NewLicence("MIT", nil)}},
+		},
+	}
+
+	expected := map[string][]string{
+		ObligationPermissive:     {"MIT"},
+		ObligationCopyleftStrong: {"GPL-2.0"},
+		ObligationUnknown:        {"LicenseRef-1"},
+	}
+	if res := doc.LicenceObligations(); !reflect.DeepEqual(res, expected) {
+		t.Errorf("Found %v (expected %v)", res, expected)
+	}
+}
+
+func TestLicenceObligation(t *testing.T) {
+	tests := map[string]string{
+		"GPL-2.0-only":      ObligationCopyleftStrong,
+		"GPL-2.0":           ObligationCopyleftStrong,
+		"LGPL-2.1-or-later": ObligationCopyleftWeak,
+		"LGPL-2.1+":         ObligationCopyleftWeak,
+		"MIT":               ObligationPermissive,
+		"LicenseRef-1":      ObligationUnknown,
+	}
+	for id, expected := range tests {
+		if cat := LicenceObligation(id); cat != expected {
+			t.Errorf("%s: found %s (expected %s)", id, cat, expected)
+		}
+	}
+
+	doc := &Document{Files: []*File{{LicenceConcluded: NewLicence("GPL-2.0", nil)}}}
+	doc.CanonicalizeLicences()
+	expected := map[string][]string{ObligationCopyleftStrong: {"GPL-2.0-only"}}
+	if res := doc.LicenceObligations(); !reflect.DeepEqual(res, expected) {
+		t.Errorf("Found %v (expected %v)", res, expected)
+	}
+}
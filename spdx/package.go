@@ -1,5 +1,14 @@
 package spdx
 
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // Represents a SPDX Package.
 type Package struct {
 	Name                 ValueStr          // Package name.
@@ -10,6 +19,7 @@ type Package struct {
 	Supplier             ValueCreator      // Package supplier. NOASSERTION is allowed.
 	Originator           ValueCreator      // Package originator. NOASSERTION is allowed.
 	VerificationCode     *VerificationCode // Package verification code.
+	FilesAnalyzed        *bool             // Whether the files of the package were analyzed, nil if unknown (SPDX 2.1).
 	Checksum             *Checksum         // Package Checksum.
 	SourceInfo           ValueStr          // Package source info.
 	LicenceConcluded     AnyLicence        // Package concluded lincence. NOASSERTION and NONE are allowed.
@@ -17,15 +27,32 @@ type Package struct {
 	LicenceDeclared      AnyLicence        // Package licence declared.
 	LicenceComments      ValueStr          // Licence comments.
 	CopyrightText        ValueStr          // Package copyright text.
+	CopyrightSentinel    Sentinel          // Whether CopyrightText is NOASSERTION or NONE (RDF only).
+	AttributionText      []ValueStr        // Attribution texts (SPDX 2.2).
 	Summary              ValueStr          // Package summary.
 	Description          ValueStr          // Package description.
+	Comment              ValueStr          // Package comment.
+	PrimaryPurpose       ValueStr          // Primary package purpose, one of PrimaryPurposes.
+	ReleaseDate          ValueDate         // Date the package was released.
+	BuiltDate            ValueDate         // Date the package was built.
+	ValidUntilDate       ValueDate         // End of support date of the package.
 	Files                []*File           // Package files.
+	Relationships        []*Relationship   // Relationships of the package.
+	ExternalRefs         []*ExternalRef    // External references (security, package manager, etc.).
+	Annotations          []*Annotation     // Annotations of the package.
+	SeeAlso              []ValueStr        // Related resources (rdfs:seeAlso, RDF only).
 	*Meta                                  // Package metadata.
 }
 
 // Returns the package metadata.
 func (pkg *Package) M() *Meta { return pkg.Meta }
 
+// Checks whether the files of the package were analyzed. They were unless
+// FilesAnalyzed is false.
+func (pkg *Package) Analyzed() bool {
+	return pkg.FilesAnalyzed == nil || *pkg.FilesAnalyzed
+}
+
 // Checks if this package is equal to `other`. Ignores metadata. Elements
 // in slices pkg.Files and pkg.LicenceInfoFromFiles must be in the same
 // order for this method to return true.
@@ -41,6 +68,11 @@ func (pkg *Package) Equal(other *Package) bool {
 		pkg.Version.Val == other.Version.Val &&
 		len(pkg.LicenceInfoFromFiles) == len(other.LicenceInfoFromFiles) &&
 		len(pkg.Files) == len(other.Files) &&
+		len(pkg.Relationships) == len(other.Relationships) &&
+		len(pkg.ExternalRefs) == len(other.ExternalRefs) &&
+		len(pkg.SeeAlso) == len(other.SeeAlso) &&
+		equalAnnotations(pkg.Annotations, other.Annotations) &&
+		len(pkg.AttributionText) == len(other.AttributionText) &&
 		pkg.DownloadLocation.Val == other.DownloadLocation.Val &&
 		pkg.HomePage.Val == other.HomePage.Val &&
 		pkg.FileName.Val == other.FileName.Val &&
@@ -48,11 +80,18 @@ func (pkg *Package) Equal(other *Package) bool {
 		pkg.CopyrightText.Val == other.CopyrightText.Val &&
 		pkg.Summary.Val == other.Summary.Val &&
 		pkg.Description.Val == other.Description.Val &&
+		pkg.Comment.Val == other.Comment.Val &&
+		pkg.PrimaryPurpose.Val == other.PrimaryPurpose.Val &&
+		pkg.ReleaseDate.V() == other.ReleaseDate.V() &&
+		pkg.BuiltDate.V() == other.BuiltDate.V() &&
+		pkg.ValidUntilDate.V() == other.ValidUntilDate.V() &&
 		pkg.SourceInfo.Val == other.SourceInfo.Val &&
 		pkg.Supplier.V() == other.Supplier.V() &&
 		pkg.Originator.V() == other.Originator.V() &&
 		pkg.Checksum.Equal(other.Checksum) &&
 		pkg.VerificationCode.Equal(other.VerificationCode) &&
+		(pkg.FilesAnalyzed == nil) == (other.FilesAnalyzed == nil) &&
+		(pkg.FilesAnalyzed == nil || *pkg.FilesAnalyzed == *other.FilesAnalyzed) &&
 		SameLicence(pkg.LicenceConcluded, other.LicenceConcluded) &&
 		SameLicence(pkg.LicenceDeclared, other.LicenceDeclared)
 
@@ -69,6 +108,26 @@ func (pkg *Package) Equal(other *Package) bool {
 			return false
 		}
 	}
+	for i, rel := range pkg.Relationships {
+		if !rel.Equal(other.Relationships[i]) {
+			return false
+		}
+	}
+	for i, ref := range pkg.ExternalRefs {
+		if !ref.Equal(other.ExternalRefs[i]) {
+			return false
+		}
+	}
+	for i, v := range pkg.SeeAlso {
+		if v.Val != other.SeeAlso[i].Val {
+			return false
+		}
+	}
+	for i, v := range pkg.AttributionText {
+		if v.Val != other.AttributionText[i].Val {
+			return false
+		}
+	}
 	return true
 }
 
@@ -116,3 +175,116 @@ func (c *Checksum) Equal(d *Checksum) bool {
 
 // Returns the checksum metadata.
 func (c *Checksum) M() *Meta { return c.Meta }
+
+// Some checksum algorithms and the length of their hexadecimal values.
+var checksumLengths = map[string]int{
+	"MD5":     32,
+	"SHA1":    40,
+	"SHA256":  64,
+	"SHA-256": 64,
+	"SHA512":  128,
+	"SHA-512": 128,
+	"SHA384":  96,
+	"SHA-384": 96,
+}
+
+// Trims and lowercases the checksum value. Returns an error if the value is
+// not hexadecimal or, for the algorithms in checksumLengths, if it doesn't
+// have the length of the algorithm.
+func (c *Checksum) Canonicalize() *ValidationError {
+	c.Value.Val = strings.ToLower(strings.TrimSpace(c.Value.Val))
+	l, ok := checksumLengths[c.Algo.V()]
+	if !isHex(c.Value.Val) || (ok && len(c.Value.Val) != l) {
+		return NewVError(fmt.Sprintf("Malformed %s checksum value %s.", c.Algo.V(), c.Value.Val), c.Meta)
+	}
+	return nil
+}
+
+// Represents an external reference of a package, such as a security advisory
+// identifier or a package manager location.
+type ExternalRef struct {
+	Category ValueStr // One of ReferenceCategories.
+	Type     ValueStr // Reference type, e.g. "cpe23Type" or "maven-central".
+	Locator  ValueStr // Reference locator, the format depends on Type.
+	Comment  ValueStr // External reference comment.
+	*Meta             // External reference metadata.
+}
+
+// Returns the external reference metadata.
+func (ref *ExternalRef) M() *Meta { return ref.Meta }
+
+// Checks if this ExternalRef is equal to `other`. Ignores metadata.
+func (ref *ExternalRef) Equal(other *ExternalRef) bool {
+	return ref == other || (ref != nil && other != nil &&
+		ref.Category.Val == other.Category.Val &&
+		ref.Type.Val == other.Type.Val &&
+		ref.Locator.Val == other.Locator.Val &&
+		ref.Comment.Val == other.Comment.Val)
+}
+
+// External reference categories.
+var ReferenceCategories = []string{"SECURITY", "PACKAGE-MANAGER", "OTHER"}
+
+// Checks whether category is one of ReferenceCategories.
+func IsReferenceCategory(category string) bool {
+	for _, c := range ReferenceCategories {
+		if category == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Primary package purposes (SPDX 2.3).
+var PrimaryPurposes = []string{
+	"APPLICATION", "FRAMEWORK", "LIBRARY", "CONTAINER", "OPERATING-SYSTEM",
+	"DEVICE", "FIRMWARE", "SOURCE", "ARCHIVE", "FILE", "INSTALL", "OTHER",
+}
+
+// Checks whether purpose is one of PrimaryPurposes.
+func IsPrimaryPurpose(purpose string) bool {
+	for _, p := range PrimaryPurposes {
+		if purpose == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Computes the verification code of pkg from the SHA1 checksums of its files:
+// the SHA1 of the sorted, concatenated checksums. The files excluded by the
+// current verification code of pkg, if any, are left out and kept in the
+// result. Returns an error if a file that is not excluded has no SHA1
+// checksum.
+func ComputeVerificationCode(pkg *Package) (*VerificationCode, error) {
+	if pkg == nil {
+		return nil, errors.New("Cannot compute the verification code of a nil package.")
+	}
+	vc := new(VerificationCode)
+	excluded := make(map[string]bool)
+	if pkg.VerificationCode != nil {
+		for _, name := range pkg.VerificationCode.ExcludedFiles {
+			excluded[name.Val] = true
+			vc.ExcludedFiles = append(vc.ExcludedFiles, name)
+		}
+	}
+
+	var sums []string
+	for _, f := range pkg.Files {
+		if f == nil || excluded[f.Name.Val] {
+			continue
+		}
+		if f.Checksum == nil || strings.ToUpper(f.Checksum.Algo.Val) != "SHA1" || f.Checksum.Value.Val == "" {
+			return nil, fmt.Errorf("File %s has no SHA1 checksum.", f.Name.Val)
+		}
+		sums = append(sums, strings.ToLower(strings.TrimSpace(f.Checksum.Value.Val)))
+	}
+	sort.Strings(sums)
+
+	h := sha1.New()
+	for _, sum := range sums {
+		h.Write([]byte(sum))
+	}
+	vc.Value.Val = hex.EncodeToString(h.Sum(nil))
+	return vc, nil
+}
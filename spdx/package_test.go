@@ -0,0 +1,33 @@
+package spdx
+
+import "testing"
+
+func TestComputeVerificationCode(t *testing.T) {
+	file := func(name, sum string) *File {
+		return &File{Name: Str(name, nil), Checksum: &Checksum{Algo: Str("SHA1", nil), Value: Str(sum, nil)}}
+	}
+	pkg := &Package{
+		Files: []*File{
+			file("b", "3AB4E1C67A2D28FCED849EE1BB76E7391B93F125"),
+			file("a", "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12"),
+			{Name: Str("excluded.spdx", nil)},
+		},
+		VerificationCode: &VerificationCode{ExcludedFiles: []ValueStr{Str("excluded.spdx", nil)}},
+	}
+
+	vc, err := ComputeVerificationCode(pkg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if vc.Value.Val != "b2d0c01ed7cdfa3dbd9d4fd06fd66761974295af" {
+		t.Errorf("Wrong verification code %s", vc.Value.Val)
+	}
+	if len(vc.ExcludedFiles) != 1 || vc.ExcludedFiles[0].Val != "excluded.spdx" {
+		t.Errorf("Wrong excluded files: %#v", vc.ExcludedFiles)
+	}
+
+	pkg.VerificationCode = nil
+	if _, err := ComputeVerificationCode(pkg); err == nil {
+		t.Error("No error for a file without checksum.")
+	}
+}
@@ -0,0 +1,79 @@
+package spdx
+
+// An element of a document and its ancestry.
+type ElementPath struct {
+	// The element: a *Package, a *File or a licence (AnyLicence).
+	Element interface{}
+
+	// The parents of the element, from the document to the direct parent,
+	// e.g. the *Document, a *Package and a *File for a licence found in a
+	// package file. Licences in sets have the sets among their parents.
+	Parents []interface{}
+}
+
+// Returns the path of every package, file and licence of the document, in
+// depth-first order. Files are listed under the package they belong to, or
+// under the document if they are not in a package. File dependencies are not
+// followed.
+func (doc *Document) Paths() []ElementPath {
+	var paths []ElementPath
+	add := func(el interface{}, parents []interface{}) []interface{} {
+		// copy, parents is shared by the siblings of el
+		p := make([]interface{}, len(parents))
+		copy(p, parents)
+		paths = append(paths, ElementPath{el, p})
+		return append(p, el)
+	}
+
+	var licence func(lic AnyLicence, parents []interface{})
+	licence = func(lic AnyLicence, parents []interface{}) {
+		if lic == nil {
+			return
+		}
+		parents = add(lic, parents)
+		switch l := lic.(type) {
+		case ConjunctiveLicenceSet:
+			walkAll(l.Members, func(m AnyLicence) { licence(m, parents) })
+		case *ConjunctiveLicenceSet:
+			walkAll(l.Members, func(m AnyLicence) { licence(m, parents) })
+		case DisjunctiveLicenceSet:
+			walkAll(l.Members, func(m AnyLicence) { licence(m, parents) })
+		case *DisjunctiveLicenceSet:
+			walkAll(l.Members, func(m AnyLicence) { licence(m, parents) })
+		case WithException:
+			licence(l.Licence, parents)
+		case *WithException:
+			licence(l.Licence, parents)
+		}
+	}
+
+	// files listed under a package, which are also in doc.Files in RDF input
+	seen := make(map[*File]bool)
+	file := func(f *File, parents []interface{}) {
+		if f == nil || seen[f] {
+			return
+		}
+		seen[f] = true
+		parents = add(f, parents)
+		licence(f.LicenceConcluded, parents)
+		walkAll(f.LicenceInfoInFile, func(lic AnyLicence) { licence(lic, parents) })
+	}
+
+	root := []interface{}{doc}
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		parents := add(pkg, root)
+		licence(pkg.LicenceConcluded, parents)
+		licence(pkg.LicenceDeclared, parents)
+		walkAll(pkg.LicenceInfoFromFiles, func(lic AnyLicence) { licence(lic, parents) })
+		for _, f := range pkg.Files {
+			file(f, parents)
+		}
+	}
+	for _, f := range doc.Files {
+		file(f, root)
+	}
+	return paths
+}
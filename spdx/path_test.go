@@ -0,0 +1,49 @@
+package spdx
+
+import "testing"
+
+func TestPaths(t *testing.T) {
+	mit := NewLicence("MIT", nil)
+	set := NewDisjunctiveSet(nil, mit, NewLicence("ISC", nil))
+	file := &File{Name: Str("main.go", nil), LicenceConcluded: set}
+	pkg := &Package{Name: Str("pkg", nil), Files: []*File{file}}
+	other := &File{Name: Str("README", nil)}
+	// as in RDF input, the package file is also a file of the document
+	doc := &Document{Packages: []*Package{pkg}, Files: []*File{file, other}}
+
+	paths := doc.Paths()
+	if len(paths) != 6 {
+		t.Fatalf("Found %d paths (expected 6): %v", len(paths), paths)
+	}
+	// licences in sets are values, which are not comparable
+	eq := func(a, b interface{}) bool {
+		if la, ok := a.(AnyLicence); ok {
+			lb, ok := b.(AnyLicence)
+			return ok && SameLicence(la, lb)
+		}
+		return a == b
+	}
+	same := func(path ElementPath, el interface{}, parents ...interface{}) bool {
+		if !eq(path.Element, el) || len(path.Parents) != len(parents) {
+			return false
+		}
+		for i, p := range parents {
+			if !eq(path.Parents[i], p) {
+				return false
+			}
+		}
+		return true
+	}
+	if !same(paths[0], pkg, doc) {
+		t.Errorf("Wrong package path: %v", paths[0])
+	}
+	if !same(paths[1], file, doc, pkg) {
+		t.Errorf("Wrong file path: %v", paths[1])
+	}
+	if !same(paths[3], mit, doc, pkg, file, set) {
+		t.Errorf("Wrong licence path: %v", paths[3])
+	}
+	if !same(paths[5], other, doc) {
+		t.Errorf("Wrong path for a file outside of packages: %v", paths[5])
+	}
+}
@@ -0,0 +1,83 @@
+package spdx
+
+// Represents a relationship between the element it belongs to (a document or
+// a package) and another SPDX element.
+type Relationship struct {
+	Type    ValueStr // Relationship type, one of RelationshipTypes, e.g. "CONTAINS"
+	Related ValueStr // URI of the related SPDX element
+	Comment ValueStr // Relationship comment
+	*Meta            // Relationship metadata
+
+	// The related element (*Document, *Package, *File, etc.), if it is
+	// defined in the same document. Nil otherwise.
+	Element interface{}
+}
+
+// Returns the relationship metadata.
+func (rel *Relationship) M() *Meta { return rel.Meta }
+
+// Checks if this Relationship is equal to `other`. Ignores metadata and
+// Element.
+func (rel *Relationship) Equal(other *Relationship) bool {
+	return rel == other || (rel != nil && other != nil &&
+		rel.Type.Val == other.Type.Val &&
+		rel.Related.Val == other.Related.Val &&
+		rel.Comment.Val == other.Comment.Val)
+}
+
+// The relationship types defined by the SPDX specification.
+var RelationshipTypes = []string{
+	"DESCRIBES",
+	"DESCRIBED_BY",
+	"CONTAINS",
+	"CONTAINED_BY",
+	"DEPENDS_ON",
+	"DEPENDENCY_OF",
+	"DEPENDENCY_MANIFEST_OF",
+	"BUILD_DEPENDENCY_OF",
+	"DEV_DEPENDENCY_OF",
+	"OPTIONAL_DEPENDENCY_OF",
+	"PROVIDED_DEPENDENCY_OF",
+	"TEST_DEPENDENCY_OF",
+	"RUNTIME_DEPENDENCY_OF",
+	"EXAMPLE_OF",
+	"GENERATES",
+	"GENERATED_FROM",
+	"ANCESTOR_OF",
+	"DESCENDANT_OF",
+	"VARIANT_OF",
+	"DISTRIBUTION_ARTIFACT",
+	"PATCH_FOR",
+	"PATCH_APPLIED",
+	"COPY_OF",
+	"FILE_ADDED",
+	"FILE_DELETED",
+	"FILE_MODIFIED",
+	"EXPANDED_FROM_ARCHIVE",
+	"DYNAMIC_LINK",
+	"STATIC_LINK",
+	"DATA_FILE_OF",
+	"TEST_CASE_OF",
+	"BUILD_TOOL_OF",
+	"DEV_TOOL_OF",
+	"TEST_OF",
+	"TEST_TOOL_OF",
+	"DOCUMENTATION_OF",
+	"OPTIONAL_COMPONENT_OF",
+	"METAFILE_OF",
+	"PACKAGE_OF",
+	"AMENDS",
+	"PREREQUISITE_FOR",
+	"HAS_PREREQUISITE",
+	"OTHER",
+}
+
+// Checks whether t is one of RelationshipTypes. It is case-sensitive.
+func IsRelationshipType(t string) bool {
+	for _, rt := range RelationshipTypes {
+		if t == rt {
+			return true
+		}
+	}
+	return false
+}
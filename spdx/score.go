@@ -0,0 +1,88 @@
+package spdx
+
+// A document quality score from 0 (worst) to 100 (best).
+type ConformanceScore int
+
+// Weights of the parts of a ConformanceScore. They add up to 100.
+const (
+	scoreMandatory   = 60 // completeness of the mandatory fields
+	scoreRecommended = 30 // presence of the recommended fields
+	scoreNoWarnings  = 10 // minus one for every warning
+)
+
+// Counts the fields present out of the fields checked.
+type fieldCounter struct {
+	present, total int
+}
+
+func (c *fieldCounter) check(ok bool) {
+	c.total++
+	if ok {
+		c.present++
+	}
+}
+
+// Returns the part of max corresponding to the fields present. If no field
+// was checked, max is returned.
+func (c *fieldCounter) score(max int) int {
+	if c.total == 0 {
+		return max
+	}
+	return max * c.present / c.total
+}
+
+// Computes the conformance score of doc: how many of the mandatory and
+// recommended fields of the document, its packages and its files are set,
+// minus the number of warnings found while parsing it. A nil document scores
+// 0.
+func ScoreDocument(doc *Document, warnings int) ConformanceScore {
+	if doc == nil {
+		return 0
+	}
+	var mandatory, recommended fieldCounter
+	set := func(v Value) bool { return v != nil && v.V() != "" }
+
+	mandatory.check(set(doc.SpecVersion))
+	mandatory.check(set(doc.DataLicence))
+	mandatory.check(doc.CreationInfo != nil && len(doc.CreationInfo.Creator) > 0)
+	mandatory.check(doc.CreationInfo != nil && set(doc.CreationInfo.Created))
+	recommended.check(doc.CreationInfo != nil && set(doc.CreationInfo.LicenceListVersion))
+	recommended.check(len(doc.Packages) > 0)
+
+	for _, pkg := range doc.Packages {
+		if pkg == nil {
+			continue
+		}
+		mandatory.check(set(pkg.Name))
+		mandatory.check(set(pkg.DownloadLocation))
+		mandatory.check(set(pkg.CopyrightText))
+		mandatory.check(set(pkg.LicenceConcluded))
+		mandatory.check(set(pkg.LicenceDeclared))
+		if len(pkg.Files) > 0 {
+			mandatory.check(pkg.VerificationCode != nil && set(pkg.VerificationCode.Value))
+		}
+		recommended.check(set(pkg.Version))
+		recommended.check(set(pkg.Supplier))
+		recommended.check(set(pkg.HomePage))
+		recommended.check(pkg.Checksum != nil && set(pkg.Checksum.Value))
+		recommended.check(set(pkg.Summary) || set(pkg.Description))
+	}
+
+	for _, file := range allFiles(doc) {
+		if file == nil {
+			continue
+		}
+		mandatory.check(set(file.Name))
+		mandatory.check(file.Checksum != nil && set(file.Checksum.Value))
+		mandatory.check(set(file.LicenceConcluded))
+		mandatory.check(set(file.CopyrightText))
+		recommended.check(len(file.LicenceInfoInFile) > 0)
+		recommended.check(set(file.Type))
+	}
+
+	score := mandatory.score(scoreMandatory) + recommended.score(scoreRecommended)
+	if warnings < scoreNoWarnings {
+		score += scoreNoWarnings - warnings
+	}
+	return ConformanceScore(score)
+}
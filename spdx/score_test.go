@@ -0,0 +1,54 @@
+package spdx
+
+import "testing"
+
+func TestScoreDocument(t *testing.T) {
+	file := &File{
+		Name:              Str("main.go", nil),
+		Type:              Str("SOURCE", nil),
+		Checksum:          &Checksum{Algo: Str("SHA1", nil), Value: Str("2fd4e1c67a2d28fced849ee1bb76e7391b93eb12", nil)},
+		LicenceConcluded:  NewLicence("MIT", nil),
+		LicenceInfoInFile: []AnyLicence{NewLicence("MIT", nil)},
+		CopyrightText:     Str("Copyright 2014 Example Ltd.", nil),
+	}
+	complete := &Document{
+		SpecVersion: Str("SPDX-1.2", nil),
+		DataLicence: Str("CC0-1.0", nil),
+		CreationInfo: &CreationInfo{
+			Creator:            []ValueCreator{NewValueCreator("Tool: spdx-go", nil)},
+			Created:            NewValueDate("2014-08-26T10:30:00Z", nil),
+			LicenceListVersion: Str("1.20", nil),
+		},
+		Packages: []*Package{{
+			Name:             Str("example", nil),
+			Version:          Str("1.0", nil),
+			DownloadLocation: Str("http://example.org/example-1.0.tar.gz", nil),
+			HomePage:         Str("http://example.org", nil),
+			Supplier:         NewValueCreator("Organization: Example Ltd.", nil),
+			Checksum:         &Checksum{Algo: Str("SHA1", nil), Value: Str("de9f2c7fd25e1b3afad3e85a0bd17d9b100db4b3", nil)},
+			VerificationCode: &VerificationCode{Value: Str("2fd4e1c67a2d28fced849ee1bb76e7391b93eb12", nil)},
+			CopyrightText:    Str("Copyright 2014 Example Ltd.", nil),
+			LicenceConcluded: NewLicence("MIT", nil),
+			LicenceDeclared:  NewLicence("MIT", nil),
+			Summary:          Str("An example package.", nil),
+			Files:            []*File{file},
+		}},
+	}
+	if score := ScoreDocument(complete, 0); score != 100 {
+		t.Errorf("Complete document scored %d.", score)
+	}
+	if score := ScoreDocument(complete, 3); score != 97 {
+		t.Errorf("Complete document with 3 warnings scored %d.", score)
+	}
+
+	sparse := &Document{
+		SpecVersion: Str("SPDX-1.2", nil),
+		Packages:    []*Package{{Name: Str("example", nil)}},
+	}
+	if score := ScoreDocument(sparse, 5); score > 40 {
+		t.Errorf("Sparse document scored %d.", score)
+	}
+	if score := ScoreDocument(nil, 0); score != 0 {
+		t.Errorf("Nil document scored %d.", score)
+	}
+}
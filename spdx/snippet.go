@@ -0,0 +1,97 @@
+package spdx
+
+// Represents a SPDX Snippet: a part of a file, given by byte or line ranges
+// (SPDX 2.1).
+type Snippet struct {
+	Name                 ValueStr        // Snippet name.
+	File                 *File           // File the snippet comes from (snippetFromFile).
+	Ranges               []*SnippetRange // Byte and line ranges of the snippet in File.
+	LicenceConcluded     AnyLicence      // Licence Concluded. NOASSERTION and NONE values allowed
+	LicenceInfoInSnippet []AnyLicence    // Licence Info in Snippet. NOASSERTION and NONE values allowed
+	LicenceComments      ValueStr        // Licence comments.
+	CopyrightText        ValueStr        // Snippet copyright text NOASSERTION and NONE allowed.
+	Comment              ValueStr        // Snippet comment.
+	*Meta                                // Snippet metadata.
+}
+
+// Returns the snippet metadata.
+func (s *Snippet) M() *Meta { return s.Meta }
+
+// Checks if this snippet is equal to `other`. Ignores metadata. Ranges and
+// elements of LicenceInfoInSnippet must be in the same order for this method
+// to return true.
+func (s *Snippet) Equal(other *Snippet) bool {
+	eq := s == other || (s != nil && other != nil &&
+		s.Name.Val == other.Name.Val &&
+		s.File.Equal(other.File) &&
+		SameLicence(s.LicenceConcluded, other.LicenceConcluded) &&
+		s.LicenceComments.Val == other.LicenceComments.Val &&
+		s.CopyrightText.Val == other.CopyrightText.Val &&
+		s.Comment.Val == other.Comment.Val &&
+		len(s.Ranges) == len(other.Ranges) &&
+		len(s.LicenceInfoInSnippet) == len(other.LicenceInfoInSnippet))
+	if !eq || s == other {
+		return eq
+	}
+	for i, r := range s.Ranges {
+		if !r.Equal(other.Ranges[i]) {
+			return false
+		}
+	}
+	for i, lic := range s.LicenceInfoInSnippet {
+		if !SameLicence(lic, other.LicenceInfoInSnippet[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Represents a range of a snippet (StartEndPointer).
+type SnippetRange struct {
+	Start *SnippetPointer // Start of the range (startPointer).
+	End   *SnippetPointer // End of the range (endPointer).
+	*Meta                 // Range metadata.
+}
+
+// Returns the range metadata.
+func (r *SnippetRange) M() *Meta { return r.Meta }
+
+// Checks if this range is equal to `other`. Ignores metadata.
+func (r *SnippetRange) Equal(other *SnippetRange) bool {
+	return r == other || (r != nil && other != nil &&
+		r.Start.Equal(other.Start) &&
+		r.End.Equal(other.End))
+}
+
+// Represents a position in a file: a byte offset (ByteOffsetPointer) or a
+// line number (LineCharPointer).
+type SnippetPointer struct {
+	Offset     ValueStr // Byte offset, set for byte offset pointers.
+	LineNumber ValueStr // Line number, set for line pointers.
+	Reference  *File    // File the pointer refers to.
+	*Meta               // Pointer metadata.
+}
+
+// Returns the pointer metadata.
+func (p *SnippetPointer) M() *Meta { return p.Meta }
+
+// Checks if this pointer is equal to `other`. Ignores metadata and the
+// referenced file.
+func (p *SnippetPointer) Equal(other *SnippetPointer) bool {
+	return p == other || (p != nil && other != nil &&
+		p.Offset.Val == other.Offset.Val &&
+		p.LineNumber.Val == other.LineNumber.Val)
+}
+
+// Checks if two slices of snippets are equal, in the same order.
+func equalSnippets(a, b []*Snippet) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, s := range a {
+		if !s.Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package spdx
+
+// Represents an element of a type that is not supported, kept with all its
+// properties (RDF only).
+type UnknownElement struct {
+	Node       string                // Node of the element, a URI or a blank node identifier.
+	Type       ValueStr              // Type URI.
+	Properties map[string][]ValueStr // Property values, by property URI, in input order.
+	*Meta                            // Element metadata.
+}
+
+// Returns the element metadata.
+func (el *UnknownElement) M() *Meta { return el.Meta }
+
+// Checks if this element is equal to `other`. Ignores metadata and the node.
+func (el *UnknownElement) Equal(other *UnknownElement) bool {
+	if el == other {
+		return true
+	}
+	if el == nil || other == nil || el.Type.Val != other.Type.Val || len(el.Properties) != len(other.Properties) {
+		return false
+	}
+	for property, values := range el.Properties {
+		others := other.Properties[property]
+		if len(values) != len(others) {
+			return false
+		}
+		for i, v := range values {
+			if v.Val != others[i].Val {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Checks if two slices of unknown elements are equal, in the same order.
+func equalUnknownElements(a, b []*UnknownElement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, el := range a {
+		if !el.Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
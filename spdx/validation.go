@@ -285,6 +285,10 @@ func (v *Validator) Document(doc *Document) bool {
 		v.Review(rev)
 	}
 
+	for _, rel := range doc.Relationships {
+		v.Relationship(rel)
+	}
+
 	v.LicReferences()
 
 	return v.HasErrors()
@@ -436,7 +440,8 @@ func (v *Validator) Review(rev *Review) bool {
 //      What: Name (Email)
 //   Valid options for "What" are: "Person" and "Organization".
 // - Package download location is not a valid URL
-// - Invalid Package Verification Code
+// - Invalid Package Verification Code, or no verification code while the
+//   package files were analyzed
 // - Invalid Package Checksum
 // - Package home page is not a valid URL
 // - No licence concluded defined
@@ -459,7 +464,7 @@ func (v *Validator) Package(pkg *Package) bool {
 
 	r = v.Url(&pkg.DownloadLocation, true, true, "Package Download Location") && r
 
-	r = v.VerificationCode(pkg.VerificationCode) && r
+	r = (!pkg.Analyzed() && pkg.VerificationCode == nil || v.VerificationCode(pkg.VerificationCode)) && r
 	r = (pkg.Checksum == nil || (pkg.Checksum.Value.V() == "" && pkg.Checksum.Algo.V() == "") || v.Checksum(pkg.Checksum)) && r
 
 	r = (pkg.HomePage.V() == "" || v.Url(&pkg.HomePage, true, true, "Package Home Page")) && r
@@ -491,6 +496,12 @@ func (v *Validator) Package(pkg *Package) bool {
 	for _, file := range pkg.Files {
 		r = v.File(file) && r
 	}
+	for _, rel := range pkg.Relationships {
+		r = v.Relationship(rel) && r
+	}
+	for _, ref := range pkg.ExternalRefs {
+		r = v.ExternalRef(ref) && r
+	}
 	v.validated[pkg] = r
 	return r
 }
@@ -653,6 +664,35 @@ func (v *Validator) VerificationCode(vc *VerificationCode) bool {
 	return r
 }
 
+// Validate Relationship.
+//
+// Adds an error if:
+// - The relationship type is not one of RelationshipTypes
+// - The related element is empty
+func (v *Validator) Relationship(rel *Relationship) bool {
+	r := true
+	if !IsRelationshipType(rel.Type.V()) {
+		v.addErr("Invalid relationship type %s.", rel.Type.M(), rel.Type.V())
+		r = false
+	}
+	return v.MandatoryText(rel.Related, false, false, "Related SPDX Element") && r
+}
+
+// Validate package ExternalRef.
+//
+// Adds an error if:
+// - The category is not one of ReferenceCategories
+// - The type or the locator are empty
+func (v *Validator) ExternalRef(ref *ExternalRef) bool {
+	r := true
+	if !IsReferenceCategory(ref.Category.V()) {
+		v.addErr("Invalid external reference category %s.", ref.Category.M(), ref.Category.V())
+		r = false
+	}
+	r = v.MandatoryText(ref.Type, false, false, "External Reference Type") && r
+	return v.MandatoryText(ref.Locator, false, false, "External Reference Locator") && r
+}
+
 // In spec verison SPDX-1.x the recommended algorithm is SHA1. If other algorithm is used, a warning is generated.
 //
 // Adds an error if:
@@ -666,23 +706,11 @@ func (v *Validator) Checksum(cksum *Checksum) bool {
 		return false
 	}
 
-	// some algorithms and hex output length
-	algos := map[string]int{
-		"MD5":     32,
-		"SHA1":    40,
-		"SHA256":  64,
-		"SHA-256": 64,
-		"SHA512":  128,
-		"SHA-512": 128,
-		"SHA384":  96,
-		"SHA-384": 96,
-	}
-
 	if v.Major == 1 && cksum.Algo.V() != "SHA1" {
 		v.addWarn("The checksum algorithm recommeded for SPDX-1.x is SHA1 but now using %s.", cksum.Meta, cksum.Algo.V())
 	}
 
-	if l, ok := algos[cksum.Algo.V()]; ok && (len(cksum.Value.V()) != l || !isHex(cksum.Value.V())) {
+	if l, ok := checksumLengths[cksum.Algo.V()]; ok && (len(cksum.Value.V()) != l || !isHex(cksum.Value.V())) {
 		v.validated[cksum] = false
 		v.addErr("Checksum value for algorithm %s must be hexadecimal of length %d.", cksum.Meta, cksum.Algo.V(), l)
 		return false
@@ -725,9 +753,21 @@ func (v *Validator) useLicence(id string, m *Meta) {
 
 // Validates an AnyLicence object, treating NONE and NOASSERTION.
 func (v *Validator) AnyLicenceOptionals(lic AnyLicence, allowSets, none, noassert bool, property string) bool {
-	t, ok := lic.(Licence)
-	if ok && ((none && t.V() == NONE) || (noassert && t.V() == NOASSERTION)) {
-		return true
+	switch t := lic.(type) {
+	case *Licence:
+		return v.AnyLicenceOptionals(*t, allowSets, none, noassert, property)
+	case Licence:
+		if (none && t.V() == NONE) || (noassert && t.V() == NOASSERTION) {
+			return true
+		}
+	case NoneLicence:
+		if none {
+			return true
+		}
+	case NoAssertionLicence:
+		if noassert {
+			return true
+		}
 	}
 	return v.AnyLicence(lic, allowSets, property)
 }
@@ -738,10 +778,13 @@ func (v *Validator) AnyLicenceOptionals(lic AnyLicence, allowSets, none, noasser
 // - Licence found and the ID is neither in the SPDX Licence List or a valid "LicenceRef-" ID.
 // - Licence Set found but not sets are allowed.
 // - Unknown licence type is found (something else than Licence, ExtractedLicence,
-//   DisjunctiveLicenceSet or ConjunctiveLicenceSet).
+//   DisjunctiveLicenceSet, ConjunctiveLicenceSet or WithException).
+// - Licence with exception has an empty exception or is applied to a set.
 // - any validation errors from validating ExtractedLicence, if the case
 func (v *Validator) AnyLicence(lic AnyLicence, allowSets bool, property string) bool {
 	switch t := lic.(type) {
+	case *Licence:
+		return v.AnyLicence(*t, allowSets, property)
 	case Licence:
 		if isLicIdRef(t.LicenceId()) {
 			v.LicenceRefId(t.LicenceId(), t.M(), property)
@@ -776,6 +819,19 @@ func (v *Validator) AnyLicence(lic AnyLicence, allowSets bool, property string)
 	case *ExtractedLicence:
 		v.useLicence(t.LicenceId(), t.M())
 		return v.ExtractedLicence(t)
+	case WithException:
+		if t.Exception == nil || t.Exception.Id.V() == "" {
+			v.addErr("%s: Licence exception cannot be empty.", t.M(), property)
+			return false
+		}
+		if t.Licence == nil {
+			v.addErr("%s: Licence with exception %s cannot be empty.", t.M(), property, t.Exception.Id.V())
+			return false
+		}
+		return v.AnyLicence(t.Licence, false, property)
+	case NoAssertionLicence, NoneLicence:
+		v.addErr("%s: %s is not allowed.", lic.M(), property, lic.LicenceId())
+		return false
 	default:
 		var m *Meta
 		if lic != nil {
@@ -1,6 +1,9 @@
 package spdx
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 // validator tester
 func hv(t *testing.T, v *Validator, result, expectedResult, errors, warnings bool) {
@@ -410,6 +413,26 @@ func TestVerificationCodeEmptyExcludedFiles(t *testing.T) {
 	}
 }
 
+func TestPackageFilesNotAnalyzed(t *testing.T) {
+	hasVerificationCodeErr := func(pkg *Package) bool {
+		validator := NewValidator()
+		validator.Package(pkg)
+		for _, err := range validator.Errors() {
+			if strings.Contains(err.Error(), "Verification Code") {
+				return true
+			}
+		}
+		return false
+	}
+	analyzed, notAnalyzed := true, false
+	if !hasVerificationCodeErr(&Package{}) || !hasVerificationCodeErr(&Package{FilesAnalyzed: &analyzed}) {
+		t.Error("Should report the missing verification code.")
+	}
+	if hasVerificationCodeErr(&Package{FilesAnalyzed: &notAnalyzed}) {
+		t.Error("Shouldn't report the missing verification code of a package whose files were not analyzed.")
+	}
+}
+
 // Test Licence Reference ID
 func TestLicenceRefIdNonNumeric(t *testing.T) {
 	val := NewLicence("LicenseRef-Abc", nil)
@@ -545,6 +568,35 @@ func TestLicenceSetNestedError(t *testing.T) {
 	hv(t, validator, validator.AnyLicence(val, true, ""), false, true, false)
 }
 
+func TestLicenceSentinels(t *testing.T) {
+	validator := NewValidator()
+	hv(t, validator, validator.AnyLicenceOptionals(NoAssertionLicence{}, true, false, true, ""), true, false, false)
+	hv(t, validator, validator.AnyLicenceOptionals(NoneLicence{}, true, true, false, ""), true, false, false)
+
+	validator = NewValidator()
+	hv(t, validator, validator.AnyLicenceOptionals(NoneLicence{}, true, false, true, ""), false, true, false)
+
+	if !SameLicence(NoAssertionLicence{}, NoAssertionLicence{NewMetaL(3)}) || SameLicence(NoAssertionLicence{}, NoneLicence{}) {
+		t.Error("Wrong comparison of sentinel licences.")
+	}
+}
+
+func TestSameUnknownLicence(t *testing.T) {
+	orLater := "http://spdx.org/rdf/terms#OrLaterOperator"
+	u := &UnknownLicence{Type: Str(orLater, nil), Members: []AnyLicence{NewLicence("GPL-2.0", nil)}}
+	same := &UnknownLicence{Type: Str(orLater, NewMetaL(4)), Members: []AnyLicence{NewLicence("GPL-2.0", nil)}}
+	other := &UnknownLicence{Type: Str(orLater, nil), Members: []AnyLicence{NewLicence("LGPL-2.1", nil)}}
+	if !SameLicence(u, u) || !SameLicence(u, same) {
+		t.Error("Same unknown licences found different.")
+	}
+	if SameLicence(u, other) || SameLicence(u, NewLicence("GPL-2.0", nil)) {
+		t.Error("Different unknown licences found the same.")
+	}
+	if !(&File{LicenceConcluded: u}).Equal(&File{LicenceConcluded: u}) {
+		t.Error("File with an unknown licence is different from itself.")
+	}
+}
+
 // ExtractedLicence
 func TestExtractedLicenceOK(t *testing.T) {
 	val := &ExtractedLicence{
@@ -694,3 +746,19 @@ func TestDefineLicence(t *testing.T) {
 }
 
 // Test document
+
+func TestValidRelationship(t *testing.T) {
+	v := NewValidator()
+	rel := &Relationship{Type: Str("CONTAINS", nil), Related: Str("SPDXRef-File", nil)}
+	if !v.Relationship(rel) || !v.Ok() {
+		t.Errorf("Valid relationship type %s not accepted: %v", rel.Type.Val, v.Errors())
+	}
+}
+
+func TestInvalidRelationship(t *testing.T) {
+	v := NewValidator()
+	rel := &Relationship{Type: Str("CONTAIN", nil), Related: Str("SPDXRef-File", nil)}
+	if v.Relationship(rel) || v.Ok() {
+		t.Errorf("Invalid relationship type %s accepted.", rel.Type.Val)
+	}
+}
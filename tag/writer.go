@@ -220,7 +220,7 @@ func (f *Formatter) Document(doc *spdx.Document) error {
 		}
 	}
 
-	if err = f.Files(doc.Files); err != nil {
+	if err = f.Files(files); err != nil {
 		return err
 	}
 
@@ -272,7 +272,7 @@ func (f *Formatter) Package(pkg *spdx.Package) error {
 		{"PackageOriginator", pkg.Originator.V()},
 		{"PackageDownloadLocation", pkg.DownloadLocation.Val},
 		{"PackageVerificationCode", verifCodeStr(pkg.VerificationCode)},
-		{"packageChecksum", cksumStr(pkg.Checksum)},
+		{"PackageChecksum", cksumStr(pkg.Checksum)},
 		{"PackageHomePage", pkg.HomePage.Val},
 		{"PackageSourceInfo", pkg.SourceInfo.Val},
 	})
@@ -285,14 +285,14 @@ func (f *Formatter) Package(pkg *spdx.Package) error {
 			return err
 		}
 	}
+	if err = f.PropertyLicenceSlice("PackageLicenseInfoFromFiles", pkg.LicenceInfoFromFiles); err != nil {
+		return err
+	}
 	if pkg.LicenceDeclared != nil {
 		if err = f.Property("PackageLicenseDeclared", pkg.LicenceDeclared.LicenceId()); err != nil {
 			return err
 		}
 	}
-	if err = f.PropertyLicenceSlice("PackageLicenseInfoFromFiles", pkg.LicenceInfoFromFiles); err != nil {
-		return err
-	}
 
 	return f.Properties([]Pair{
 		{"PackageLicenseComments", pkg.LicenceComments.Val},
@@ -340,6 +340,23 @@ func (f *Formatter) File(file *spdx.File) error {
 	err = f.Properties([]Pair{
 		{"LicenseComments", file.LicenceComments.Val},
 		{"FileCopyrightText", file.CopyrightText.Val},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, artif := range file.ArtifactOf {
+		err = f.Properties([]Pair{
+			{"ArtifactOfProjectName", artif.Name.Val},
+			{"ArtifactOfProjectHomePage", artif.HomePage.Val},
+			{"ArtifactOfProjectURI", artif.ProjectUri.Val},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = f.Properties([]Pair{
 		{"FileComment", file.Comment.Val},
 		{"FileNotice", file.Notice.Val},
 	})
@@ -3,6 +3,7 @@ package tag
 import (
 	"bytes"
 	"github.com/vladvelici/spdx-go/spdx"
+	"strings"
 	"testing"
 )
 
@@ -157,3 +158,81 @@ func TestProperty(t *testing.T) {
 		buf.Reset()
 	}
 }
+
+func TestPackageChecksumAndLicences(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter(buf)
+
+	pkg := &spdx.Package{
+		Name:                 spdx.Str("spdx-go", nil),
+		Checksum:             &spdx.Checksum{Algo: spdx.Str("SHA1", nil), Value: spdx.Str("testvalue", nil)},
+		LicenceConcluded:     spdx.NewLicence("MIT", nil),
+		LicenceInfoFromFiles: []spdx.AnyLicence{spdx.NewLicence("ISC", nil)},
+		LicenceDeclared:      spdx.NewLicence("Apache-2.0", nil),
+	}
+	if err := f.Package(pkg); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := "PackageName: spdx-go\n" +
+		"PackageChecksum: SHA1: testvalue\n" +
+		"PackageLicenseConcluded: MIT\n" +
+		"PackageLicenseInfoFromFiles: ISC\n" +
+		"PackageLicenseDeclared: Apache-2.0\n"
+	if res := buf.String(); res != expected {
+		t.Errorf("Incorrect package. Printed %#v but expected %#v", res, expected)
+	}
+}
+
+func TestFileArtifactOf(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter(buf)
+
+	file := &spdx.File{
+		Name: spdx.Str("./main.go", nil),
+		ArtifactOf: []*spdx.ArtifactOf{
+			{Name: spdx.Str("upstream", nil), HomePage: spdx.Str("http://example.org", nil), ProjectUri: spdx.Str("http://example.org/upstream", nil)},
+			{Name: spdx.Str("other", nil)},
+		},
+		Comment: spdx.Str("comment", nil),
+	}
+	if err := f.File(file); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := "FileName: ./main.go\n" +
+		"\nArtifactOfProjectName: upstream\n" +
+		"ArtifactOfProjectHomePage: http://example.org\n" +
+		"ArtifactOfProjectURI: http://example.org/upstream\n" +
+		"\nArtifactOfProjectName: other\n" +
+		"FileComment: <text>comment</text>\n"
+	if res := buf.String(); res != expected {
+		t.Errorf("Incorrect file. Printed %#v but expected %#v", res, expected)
+	}
+}
+
+func TestDocumentPackageFiles(t *testing.T) {
+	buf := new(bytes.Buffer)
+	f := NewFormatter(buf)
+
+	file := &spdx.File{Name: spdx.Str("./main.go", nil)}
+	doc := &spdx.Document{
+		SpecVersion: spdx.Str("SPDX-1.2", nil),
+		Packages:    []*spdx.Package{{Name: spdx.Str("spdx-go", nil), Files: []*spdx.File{file}}},
+	}
+	if err := f.Document(doc); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if n := strings.Count(buf.String(), "FileName: ./main.go\n"); n != 1 {
+		t.Errorf("Package file written %d times in:\n%s", n, buf.String())
+	}
+
+	buf.Reset()
+	doc.Files = []*spdx.File{file}
+	if err := f.Document(doc); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if n := strings.Count(buf.String(), "FileName: ./main.go\n"); n != 1 {
+		t.Errorf("Package file written %d times in:\n%s", n, buf.String())
+	}
+}